@@ -0,0 +1,80 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ApplyBatch apply transit to each item and collect results by item index
+// errors of the failed items wrapped with the item index and returned as MultiError, failed items get nil result
+// items left after the context is done get the context error
+func (w *Workflow) ApplyBatch(ctx context.Context, items []Data, transit fmt.Stringer) ([]Data, error) {
+	res := make([]Data, len(items))
+
+	var errs MultiError
+	for i, item := range items {
+		err := ctx.Err()
+		if err == nil {
+			res[i], err = w.Apply(ctx, item, transit)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", i, err))
+		}
+	}
+	if len(errs) > 0 {
+		return res, errs
+	}
+
+	return res, nil
+}
+
+// ApplyBatchParallel apply transit to items in parallel with bounded concurrency, results keep items order
+// concurrency less than 1 means unlimited, errors wrapped with the item index
+// without failFast errors of all failed items returned as MultiError in items order
+// with failFast the first error returned and cancel context of the remaining items, items not applied get nil result
+func (w *Workflow) ApplyBatchParallel(ctx context.Context, items []Data, transit fmt.Stringer, concurrency int, failFast bool) ([]Data, error) {
+	res := make([]Data, len(items))
+	errs := make([]error, len(items))
+
+	var g *errgroup.Group
+	if failFast {
+		g, ctx = errgroup.WithContext(ctx)
+	} else {
+		g = &errgroup.Group{}
+	}
+	if concurrency > 0 {
+		g.SetLimit(concurrency)
+	}
+
+	for i, item := range items {
+		i, item := i, item
+		g.Go(func() error {
+			err := ctx.Err()
+			if err == nil {
+				res[i], err = w.Apply(ctx, item, transit)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("item %d: %w", i, err)
+				return errs[i]
+			}
+
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	if failFast || err == nil {
+		return res, err
+	}
+
+	var all MultiError
+	for _, err := range errs {
+		if err != nil {
+			all = append(all, err)
+		}
+	}
+
+	return res, all
+}
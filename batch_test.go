@@ -0,0 +1,94 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_ApplyBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		calls++
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	res, err := w.ApplyBatch(ctx, []Data{
+		testData{state: newState},
+		testData{state: cancelState},
+		testData{state: newState},
+	}, toDone)
+	require.Equal(t, []Data{testData{state: doneState}, nil, testData{state: doneState}}, res)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	require.EqualError(t, err, `item 1: transit "to done" from "cancel": transit not allowed`)
+
+	res, err = w.ApplyBatch(ctx, []Data{testData{state: newState}}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, []Data{testData{state: doneState}}, res)
+
+	calls = 0
+	cancel()
+	res, err = w.ApplyBatch(ctx, []Data{testData{state: newState}, testData{state: newState}}, toDone)
+	require.Equal(t, []Data{nil, nil}, res)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.EqualError(t, err, "item 0: context canceled; item 1: context canceled")
+	require.Equal(t, 0, calls)
+}
+
+func TestWorkflow_ApplyBatchParallel(t *testing.T) {
+	ctx := context.Background()
+	var running, peak int32
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		cur := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			prev := atomic.LoadInt32(&peak)
+			if cur <= prev || atomic.CompareAndSwapInt32(&peak, prev, cur) {
+				break
+			}
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	items := make([]Data, 20)
+	expected := make([]Data, 20)
+	for i := range items {
+		items[i] = testData{state: newState}
+		expected[i] = testData{state: doneState}
+	}
+	res, err := w.ApplyBatchParallel(ctx, items, toDone, 3, false)
+	require.Nil(t, err)
+	require.Equal(t, expected, res)
+	require.LessOrEqual(t, atomic.LoadInt32(&peak), int32(3))
+
+	items[5] = testData{state: cancelState}
+	res, err = w.ApplyBatchParallel(ctx, items, toDone, 0, false)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	require.EqualError(t, err, `item 5: transit "to done" from "cancel": transit not allowed`)
+	require.Nil(t, res[5])
+	require.Equal(t, testData{state: doneState}, res[19])
+
+	items[12] = testData{state: cancelState}
+	_, err = w.ApplyBatchParallel(ctx, items, toDone, 2, false)
+	var errs MultiError
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 2)
+	require.EqualError(t, err, `item 5: transit "to done" from "cancel": transit not allowed; `+
+		`item 12: transit "to done" from "cancel": transit not allowed`)
+
+	res, err = w.ApplyBatchParallel(ctx, []Data{testData{state: cancelState}, testData{state: newState}}, toDone, 1, true)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	require.Equal(t, []Data{nil, nil}, res)
+}
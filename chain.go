@@ -0,0 +1,68 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CompensateError error of the failed chain step with errors of the failed compensations
+type CompensateError struct {
+	Err        error
+	Compensate []error
+}
+
+func (e *CompensateError) Error() string {
+	msgs := make([]string, len(e.Compensate))
+	for i, err := range e.Compensate {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%v: compensate: %s", e.Err, strings.Join(msgs, "; "))
+}
+
+// Unwrap get error of the failed step
+func (e *CompensateError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyChain apply transits in order, when a step failed run Compensate transit of the applied steps in reverse order
+// steps without Compensate skipped, returns data after compensation with the step error
+// or CompensateError when any compensation failed
+// compensations keep values of the context but not its deadline and cancellation so a step failed by the deadline is rolled back
+func (w *Workflow) ApplyChain(ctx context.Context, data Data, transits ...fmt.Stringer) (Data, error) {
+	applied := make([]Result, 0, len(transits))
+	for _, transit := range transits {
+		res, err := w.ApplyResult(ctx, data, transit)
+		if err != nil {
+			return w.compensate(detachedContext{parent: ctx}, data, applied, err)
+		}
+		applied = append(applied, res)
+		data = res.Data
+	}
+
+	return data, nil
+}
+
+// compensate applied steps in reverse order and collect compensation errors
+func (w *Workflow) compensate(ctx context.Context, data Data, applied []Result, err error) (Data, error) {
+	var errs []error
+	for i := len(applied) - 1; i >= 0; i-- {
+		if applied[i].Transition == nil || applied[i].Transition.Compensate == nil {
+			continue
+		}
+
+		res, cerr := w.Apply(ctx, data, applied[i].Transition.Compensate)
+		if cerr != nil {
+			errs = append(errs, fmt.Errorf("step %q: %w", applied[i].Transit, cerr))
+			continue
+		}
+		data = res
+	}
+
+	if len(errs) > 0 {
+		return data, &CompensateError{Err: err, Compensate: errs}
+	}
+
+	return data, err
+}
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_ApplyChain(t *testing.T) {
+	ctx := context.Background()
+	var ex []fmt.Stringer
+	errShip := errors.New("ship")
+	reserved, shipped := testState("reserved"), testState("shipped")
+	reserve, release, ship := testTransit("reserve"), testTransit("release"), testTransit("ship")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		transit, _ := TransitFromContext(ctx)
+		ex = append(ex, transit)
+		if dst == shipped {
+			return nil, errShip
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(reserve, &Transition{Dst: reserved, Src: []fmt.Stringer{newState}, Compensate: release}))
+	require.Nil(t, w.Add(release, &Transition{Dst: newState, Src: []fmt.Stringer{reserved}}))
+	require.Nil(t, w.Add(ship, &Transition{Dst: shipped, Src: []fmt.Stringer{reserved}}))
+
+	data, err := w.ApplyChain(ctx, testData{}, toNew, reserve)
+	require.Nil(t, err)
+	require.Equal(t, reserved, data.GetState())
+
+	ex = nil
+	data, err = w.ApplyChain(ctx, testData{}, toNew, reserve, ship)
+	require.True(t, errors.Is(err, errShip))
+	require.Equal(t, newState, data.GetState())
+	require.Equal(t, []fmt.Stringer{toNew, reserve, ship, release}, ex)
+
+	require.Nil(t, w.Replace(release, &Transition{Dst: newState, Src: []fmt.Stringer{doneState}}))
+	_, err = w.ApplyChain(ctx, testData{}, toNew, reserve, ship)
+	require.True(t, errors.Is(err, errShip))
+	var cerr *CompensateError
+	require.True(t, errors.As(err, &cerr))
+	require.Len(t, cerr.Compensate, 1)
+	require.True(t, errors.Is(cerr.Compensate[0], ErrTransitNotAllowed))
+	require.EqualError(t, err, `transit "ship": ship: compensate: step "reserve": transit "release" from "reserved": transit not allowed`)
+}
+
+func TestWorkflow_ApplyChain_Deadline(t *testing.T) {
+	reserved, shipped := testState("reserved"), testState("shipped")
+	reserve, release, ship := testTransit("reserve"), testTransit("release"), testTransit("ship")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		if dst == shipped {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(reserve, &Transition{Dst: reserved, Src: []fmt.Stringer{newState}, Compensate: release}))
+	require.Nil(t, w.Add(release, &Transition{Dst: newState, Src: []fmt.Stringer{reserved}}))
+	require.Nil(t, w.Add(ship, &Transition{Dst: shipped, Src: []fmt.Stringer{reserved}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	data, err := w.ApplyChain(ctx, testData{state: newState}, reserve, ship)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	var cerr *CompensateError
+	require.False(t, errors.As(err, &cerr))
+	require.Equal(t, newState, data.GetState())
+}
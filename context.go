@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type ctxKey int
+
+const (
+	callKey ctxKey = iota
+	debugKey
+)
+
+// applyCall values of the applying transit, one value per call set to the context by withTransit
+// resolveApply fill the transition, src and dst when the transition resolved
+type applyCall struct {
+	transit    fmt.Stringer
+	transition *Transition
+	src        fmt.Stringer
+	dst        fmt.Stringer
+}
+
+// withTransit set the call of the transit to the context
+func withTransit(ctx context.Context, transit fmt.Stringer) context.Context {
+	return context.WithValue(ctx, callKey, &applyCall{transit: transit})
+}
+
+// callFromContext get the call of the applying transit
+func callFromContext(ctx context.Context) *applyCall {
+	call, _ := ctx.Value(callKey).(*applyCall)
+
+	return call
+}
+
+// TransitFromContext get the name of the applying transit
+// available for global and transition middleware
+func TransitFromContext(ctx context.Context) (fmt.Stringer, bool) {
+	call := callFromContext(ctx)
+	if call == nil || call.transit == nil {
+		return nil, false
+	}
+
+	return call.transit, true
+}
+
+// DstFromContext get the destination state of the applying transit
+// available for transition middleware and apply, global middleware run before transition resolved
+func DstFromContext(ctx context.Context) (fmt.Stringer, bool) {
+	call := callFromContext(ctx)
+	if call == nil || call.dst == nil {
+		return nil, false
+	}
+
+	return call.dst, true
+}
+
+// SrcFromContext get the state of the data before the applying transit, false when data has no state
+// available for transition middleware and apply
+func SrcFromContext(ctx context.Context) (fmt.Stringer, bool) {
+	call := callFromContext(ctx)
+	if call == nil || call.src == nil {
+		return nil, false
+	}
+
+	return call.src, true
+}
+
+// TransitionFromContext get the resolved transition of the applying transit, transition must not be changed
+// available for transition middleware and apply
+func TransitionFromContext(ctx context.Context) (*Transition, bool) {
+	call := callFromContext(ctx)
+	if call == nil || call.transition == nil {
+		return nil, false
+	}
+
+	return call.transition, true
+}
+
+// detachedContext keep values of the parent without its deadline and cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,83 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransitFromContext(t *testing.T) {
+	ctx := context.Background()
+	_, ok := TransitFromContext(ctx)
+	require.False(t, ok)
+
+	var transits []fmt.Stringer
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		transit, ok := TransitFromContext(ctx)
+		require.True(t, ok)
+		transits = append(transits, transit)
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState}))
+
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, testData{}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{toNew, toDone}, transits)
+}
+
+func TestDstFromContext(t *testing.T) {
+	ctx := context.Background()
+	_, ok := DstFromContext(ctx)
+	require.False(t, ok)
+
+	var dsts []fmt.Stringer
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}, WithMiddleware(func(ctx context.Context, data Data, next Process) (Data, error) {
+		_, ok := DstFromContext(ctx)
+		require.False(t, ok)
+		return next(ctx, data)
+	}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, func(ctx context.Context, data Data, next Process) (Data, error) {
+		dst, ok := DstFromContext(ctx)
+		require.True(t, ok)
+		dsts = append(dsts, dst)
+		return next(ctx, data)
+	}))
+
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{newState}, dsts)
+}
+
+func TestTransitionFromContext(t *testing.T) {
+	ctx := context.Background()
+	_, ok := TransitionFromContext(ctx)
+	require.False(t, ok)
+
+	var ex []string
+	transition := &Transition{Dst: doneState, Src: []fmt.Stringer{newState}, Meta: map[string]any{"template": "done.html"}}
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		tr, ok := TransitionFromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, dst, tr.Dst)
+		return data, nil
+	})
+	require.Nil(t, w.Add(toDone, transition, func(ctx context.Context, data Data, next Process) (Data, error) {
+		src, ok := SrcFromContext(ctx)
+		require.True(t, ok)
+		tr, ok := TransitionFromContext(ctx)
+		require.True(t, ok)
+		ex = append(ex, fmt.Sprintf("%v %v", src, tr.Meta["template"]))
+		return next(ctx, data)
+	}))
+
+	_, err := w.Apply(ctx, testData{state: newState}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, []string{"new done.html"}, ex)
+}
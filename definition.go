@@ -0,0 +1,203 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition serializable description of the workflow transitions
+type Definition struct {
+	Transitions []TransitionDefinition `json:"transitions" yaml:"transitions"`
+}
+
+// TransitionDefinition serializable transition, states stored by String()
+type TransitionDefinition struct {
+	Name string   `json:"name" yaml:"name"`
+	Src  []string `json:"src,omitempty" yaml:"src,omitempty"`
+	// ExceptSrc states not allowed for the transition
+	ExceptSrc []string `json:"except_src,omitempty" yaml:"except_src,omitempty"`
+	Dst       string   `json:"dst" yaml:"dst"`
+	// Meta transition labels, values must be serializable
+	Meta map[string]any `json:"meta,omitempty" yaml:"meta,omitempty"`
+	// Middleware names registered by RegisterMiddleware or WithNamedMiddleware
+	Middleware []string `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+}
+
+// MarshalJSON encode transitions sorted by name
+// only middleware names are serialized, middleware, guard and DstFunc with Dst skipped
+// transition with SrcFunc or only DstFunc returns error matched by ErrNotSerializable
+func (w *Workflow) MarshalJSON() ([]byte, error) {
+	trs := w.Transitions()
+	for _, nt := range trs {
+		if err := nt.Transition.serializable(nt.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(definition(trs))
+}
+
+const (
+	// anonymous name of the middleware not registered by name
+	anonymous = "<anonymous>"
+	// funcState src or dst of the transition resolved by SrcFunc or DstFunc, rejected by Load
+	funcState = "<func>"
+)
+
+// serializable check src and dst of the transition can be described by states
+func (tr *Transition) serializable(name fmt.Stringer) error {
+	if tr.SrcFunc != nil {
+		return fmt.Errorf("transit %q src func: %w", name, ErrNotSerializable)
+	}
+	if tr.Dst == nil && tr.DstFunc != nil {
+		return fmt.Errorf("transit %q dst func: %w", name, ErrNotSerializable)
+	}
+
+	return nil
+}
+
+// Snapshot get description of the transitions sorted by name for diagnostics
+// Middleware lists the whole chain of the transition, registered middleware by the name and others as "<anonymous>"
+// src of SrcFunc and dst of DstFunc without Dst shown as "<func>"
+// so the snapshot with anonymous middleware or funcs can't be loaded by Load
+func (w *Workflow) Snapshot() Definition {
+	trs := w.Transitions()
+	def := definition(trs)
+	for i, nt := range trs {
+		def.Transitions[i].Middleware = append([]string(nil), nt.Transition.layers...)
+	}
+
+	return def
+}
+
+// definition build serializable transitions
+func definition(trs []NamedTransition) Definition {
+	def := Definition{
+		Transitions: make([]TransitionDefinition, len(trs)),
+	}
+
+	for i, nt := range trs {
+		td := TransitionDefinition{
+			Name:       nt.Name.String(),
+			Dst:        stateKey(nt.Transition.Dst),
+			Meta:       nt.Transition.Meta,
+			Middleware: nt.Transition.MiddlewareNames,
+		}
+		if nt.Transition.Dst == nil && nt.Transition.DstFunc != nil {
+			td.Dst = funcState
+		}
+		if nt.Transition.SrcFunc != nil {
+			td.Src = []string{funcState}
+		} else if !nt.Transition.anySrc() {
+			for _, src := range nt.Transition.Src {
+				td.Src = append(td.Src, src.String())
+			}
+		}
+		for _, src := range nt.Transition.ExceptSrc {
+			td.ExceptSrc = append(td.ExceptSrc, src.String())
+		}
+		def.Transitions[i] = td
+	}
+
+	return def
+}
+
+// Load create workflow by the definition
+// resolve convert serialized transit names and states to the stringer values used by the caller
+// named middleware of the definition must be registered by WithNamedMiddleware option
+// other middleware can be attached afterward by Replace
+func Load(def Definition, resolve func(string) fmt.Stringer, apply Apply, opts ...Option) (*Workflow, error) {
+	w, err := NewWorkflowE(apply, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, td := range def.Transitions {
+		name, tr, err := td.resolve(resolve)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.Add(name, tr); err != nil {
+			return nil, fmt.Errorf("transit %q: %w", td.Name, err)
+		}
+	}
+
+	return w, nil
+}
+
+// LoadYAML decode the definition in the same schema as json and create workflow by Load
+// unknown fields are rejected to catch typos in the config
+func LoadYAML(r io.Reader, resolve func(string) fmt.Stringer, apply Apply, opts ...Option) (*Workflow, error) {
+	var def Definition
+
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+
+	return Load(def, resolve, apply, opts...)
+}
+
+// resolve transit name and states
+func (td TransitionDefinition) resolve(resolve func(string) fmt.Stringer) (fmt.Stringer, *Transition, error) {
+	for _, state := range append(append([]string{td.Dst}, td.Src...), td.ExceptSrc...) {
+		if state == funcState {
+			return nil, nil, fmt.Errorf("transit %q state %q: %w", td.Name, state, ErrNotSerializable)
+		}
+	}
+
+	name := resolve(td.Name)
+	if name == nil {
+		return nil, nil, fmt.Errorf("transit %q: %w", td.Name, ErrNotResolved)
+	}
+
+	dst := resolve(td.Dst)
+	if dst == nil {
+		return nil, nil, fmt.Errorf("transit %q: dst %q: %w", td.Name, td.Dst, ErrNotResolved)
+	}
+
+	tr := &Transition{Dst: dst, Meta: td.Meta, MiddlewareNames: td.Middleware}
+	for _, s := range td.Src {
+		src := resolve(s)
+		if src == nil {
+			return nil, nil, fmt.Errorf("transit %q: src %q: %w", td.Name, s, ErrNotResolved)
+		}
+		tr.Src = append(tr.Src, src)
+	}
+	for _, s := range td.ExceptSrc {
+		src := resolve(s)
+		if src == nil {
+			return nil, nil, fmt.Errorf("transit %q: except src %q: %w", td.Name, s, ErrNotResolved)
+		}
+		tr.ExceptSrc = append(tr.ExceptSrc, src)
+	}
+
+	return name, tr, nil
+}
+
+// Equal check both workflows have the same transit names with the same Src, ExceptSrc sets and Dst by String()
+// transitions with the same name compared in registration order, middleware, guard and funcs ignored
+// nil, empty src and src with AnyState are the same
+func Equal(a, b *Workflow) bool {
+	at, bt := a.Transitions(), b.Transitions()
+	if len(at) != len(bt) {
+		return false
+	}
+
+	for i := range at {
+		atr, btr := at[i].Transition, bt[i].Transition
+		if at[i].Name.String() != bt[i].Name.String() ||
+			stateKey(atr.Dst) != stateKey(btr.Dst) ||
+			atr.anySrc() != btr.anySrc() ||
+			!atr.anySrc() && !sameStates(atr.Src, btr.Src) ||
+			!sameStates(atr.ExceptSrc, btr.ExceptSrc) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,254 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_MarshalJSON(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toCancel, &Transition{
+		Dst:  cancelState,
+		Src:  []fmt.Stringer{newState, doneState},
+		Meta: map[string]any{"label": "Cancel", "role": "admin"},
+	}))
+
+	tr, ok := w.Transition(toCancel)
+	require.True(t, ok)
+	require.Equal(t, "Cancel", tr.Meta["label"])
+	tr.Meta["label"] = "changed"
+
+	data, err := json.Marshal(w)
+	require.Nil(t, err)
+	require.JSONEq(t, `{"transitions":[
+		{"name":"to cancel","src":["new","done"],"dst":"cancel","meta":{"label":"Cancel","role":"admin"}},
+		{"name":"to new","dst":"new"}
+	]}`, string(data))
+}
+
+func testResolve(name string) fmt.Stringer {
+	for _, s := range []fmt.Stringer{toNew, toDone, toCancel, newState, doneState, cancelState} {
+		if s.String() == name {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	var def Definition
+	require.Nil(t, json.Unmarshal([]byte(`{"transitions":[
+		{"name":"to new","dst":"new"},
+		{"name":"to done","src":["new"],"dst":"done"}
+	]}`), &def))
+
+	w, err := Load(def, testResolve, func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{doneState, newState}, w.States())
+
+	exNew, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	exDone, err := w.Apply(ctx, exNew, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, exDone.GetState())
+
+	data, err := json.Marshal(w)
+	require.Nil(t, err)
+	require.JSONEq(t, `{"transitions":[
+		{"name":"to done","src":["new"],"dst":"done"},
+		{"name":"to new","dst":"new"}
+	]}`, string(data))
+}
+
+func TestLoad_Error(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+
+	_, err := Load(Definition{Transitions: []TransitionDefinition{
+		{Name: "to done", Src: []string{"draft"}, Dst: "done"},
+	}}, testResolve, apply)
+	require.True(t, errors.Is(err, ErrNotResolved))
+	require.EqualError(t, err, `transit "to done": src "draft": not resolved`)
+
+	_, err = Load(Definition{Transitions: []TransitionDefinition{
+		{Name: "to new", Dst: "new"},
+		{Name: "to new", Dst: "done"},
+	}}, testResolve, apply)
+	require.True(t, errors.Is(err, ErrDuplicateTransit))
+}
+
+func TestEqual(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+	w := NewWorkflow(apply)
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, func(ctx context.Context, data Data, next Process) (Data, error) {
+		return next(ctx, data)
+	}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	data, err := json.Marshal(w)
+	require.Nil(t, err)
+	var def Definition
+	require.Nil(t, json.Unmarshal(data, &def))
+	loaded, err := Load(def, testResolve, apply)
+	require.Nil(t, err)
+	require.True(t, Equal(w, loaded))
+
+	other := NewWorkflow(apply)
+	require.Nil(t, other.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, other.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{doneState, newState}}))
+	require.True(t, Equal(w, other))
+
+	require.Nil(t, other.Replace(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{doneState}}))
+	require.False(t, Equal(w, other))
+	require.Nil(t, other.Replace(toCancel, &Transition{Dst: doneState, Src: []fmt.Stringer{doneState, newState}}))
+	require.False(t, Equal(w, other))
+	require.True(t, other.Remove(toCancel))
+	require.False(t, Equal(w, other))
+}
+
+func TestEqual_SnapshotLoad(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+	w := NewWorkflow(apply)
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{AnyState}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, ExceptSrc: []fmt.Stringer{cancelState}}))
+
+	data, err := json.Marshal(w.Snapshot())
+	require.Nil(t, err)
+	var def Definition
+	require.Nil(t, json.Unmarshal(data, &def))
+	loaded, err := Load(def, testResolve, apply)
+	require.Nil(t, err)
+	require.True(t, Equal(w, loaded))
+	require.True(t, Equal(loaded, w))
+
+	loaded, err = Load(Definition{Transitions: []TransitionDefinition{
+		{Name: "to new", Dst: "new", Src: []string{"done"}},
+		{Name: "to done", Dst: "done"},
+		{Name: "to cancel", Dst: "cancel", ExceptSrc: []string{"cancel"}},
+	}}, testResolve, apply)
+	require.Nil(t, err)
+	require.False(t, Equal(w, loaded))
+}
+
+func TestWorkflow_MarshalJSON_Func(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+	w := NewWorkflow(apply)
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, SrcFunc: func(state fmt.Stringer) bool {
+		return state == newState
+	}}))
+	require.Nil(t, w.Add(toDone, &Transition{DstFunc: func(ctx context.Context, data Data) (fmt.Stringer, error) {
+		return doneState, nil
+	}}))
+
+	_, err := json.Marshal(w)
+	require.True(t, errors.Is(err, ErrNotSerializable))
+
+	def := w.Snapshot()
+	require.Equal(t, Definition{Transitions: []TransitionDefinition{
+		{Name: "to cancel", Src: []string{"<func>"}, Dst: "cancel"},
+		{Name: "to done", Dst: "<func>"},
+	}}, def)
+	_, err = Load(def, testResolve, apply)
+	require.True(t, errors.Is(err, ErrNotSerializable))
+	require.EqualError(t, err, `transit "to cancel" state "<func>": not serializable`)
+
+	require.True(t, w.Remove(toCancel))
+	_, err = Load(w.Snapshot(), testResolve, apply)
+	require.True(t, errors.Is(err, ErrNotSerializable))
+}
+
+func TestLoadYAML(t *testing.T) {
+	ctx := context.Background()
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}
+
+	w, err := LoadYAML(strings.NewReader(`
+transitions:
+  - name: to new
+    dst: new
+  - name: to done
+    src: [new]
+    dst: done
+    meta:
+      label: Done
+`), testResolve, apply)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{doneState, newState}, w.States())
+	tr, ok := w.Transition(toDone)
+	require.True(t, ok)
+	require.Equal(t, "Done", tr.Meta["label"])
+
+	ex, err := w.Apply(ctx, testData{state: newState}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+
+	_, err = LoadYAML(strings.NewReader(`
+transitions:
+  - name: to done
+    src: [draft]
+    dst: done
+`), testResolve, apply)
+	require.True(t, errors.Is(err, ErrNotResolved))
+	require.EqualError(t, err, `transit "to done": src "draft": not resolved`)
+
+	_, err = LoadYAML(strings.NewReader(`
+transitions:
+  - name: to done
+    source: [new]
+    dst: done
+`), testResolve, apply)
+	require.NotNil(t, err)
+}
+
+func TestWorkflow_Snapshot(t *testing.T) {
+	mwf := &testMWFactory{}
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}, WithNamedMiddleware("audit", Named("audit", mwf.Success(t, "audit"))))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toCancel, &Transition{
+		Dst:             cancelState,
+		Src:             []fmt.Stringer{newState},
+		MiddlewareNames: []string{"audit"},
+		Middlewares:     []Middleware{mwf.Success(t, "log")},
+		Middleware:      mwf.Success(t, "cancel"),
+	}, mwf.Success(t, "custom")))
+
+	require.Equal(t, Definition{Transitions: []TransitionDefinition{
+		{Name: "to cancel", Src: []string{"new"}, Dst: "cancel", Middleware: []string{"<anonymous>", "audit", "<anonymous>", "<anonymous>"}},
+		{Name: "to new", Dst: "new"},
+	}}, w.Snapshot())
+
+	data, err := json.Marshal(w)
+	require.Nil(t, err)
+	require.JSONEq(t, `{"transitions":[
+		{"name":"to cancel","src":["new"],"dst":"cancel","middleware":["audit"]},
+		{"name":"to new","dst":"new"}
+	]}`, string(data))
+}
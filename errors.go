@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"errors"
+	"strings"
+)
+
+// MultiError aggregate several errors, errors.Is and errors.As match any of the errors
+type MultiError []error
+
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Is report whether any error matches the target
+func (e MultiError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As find the first error that matches the target
+func (e MultiError) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// invalidError problem of the workflow matched by ErrInvalidWorkflow
+type invalidError string
+
+func (e invalidError) Error() string {
+	return string(e)
+}
+
+func (e invalidError) Is(target error) bool {
+	return target == ErrInvalidWorkflow
+}
+
+// notAllowedError transit not allowed with the reason matched by errors.Is, message keeps only ErrTransitNotAllowed
+type notAllowedError struct {
+	reason error
+}
+
+func (e notAllowedError) Error() string {
+	return ErrTransitNotAllowed.Error()
+}
+
+func (e notAllowedError) Unwrap() error {
+	return e.reason
+}
+
+// guardError error returned by the guard of the transition
+type guardError struct {
+	err error
+}
+
+func (e guardError) Error() string {
+	return e.err.Error()
+}
+
+func (e guardError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,29 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiError(t *testing.T) {
+	errFirst := errors.New("first")
+	var err error = MultiError{
+		fmt.Errorf("item 0: %w", errFirst),
+		fmt.Errorf("item 1: %w", panicError{r: "boom"}),
+	}
+
+	require.EqualError(t, err, "item 0: first; item 1: panic: boom")
+	require.True(t, errors.Is(err, errFirst))
+	require.True(t, errors.Is(err, ErrPanic))
+	require.False(t, errors.Is(err, ErrFrozen))
+
+	var pe panicError
+	require.True(t, errors.As(err, &pe))
+	require.Equal(t, "boom", pe.r)
+
+	var ce *CompensateError
+	require.False(t, errors.As(err, &ce))
+}
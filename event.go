@@ -0,0 +1,60 @@
+package workflow
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// eventBuffer size of the subscription channel
+const eventBuffer = 16
+
+// Event emitted after successful apply
+type Event struct {
+	Transit fmt.Stringer
+	From    fmt.Stringer
+	To      fmt.Stringer
+	Data    Data
+}
+
+// Subscribe get channel of the events
+// event dropped when channel buffer is full so slow consumer not block apply
+func (w *Workflow) Subscribe() <-chan Event {
+	ch := make(chan Event, eventBuffer)
+
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	if w.subs == nil {
+		w.subs = make(map[<-chan Event]chan Event)
+	}
+	w.subs[ch] = ch
+	atomic.AddInt32(&w.subCount, 1)
+
+	return ch
+}
+
+// Unsubscribe stop sending events and close the channel
+func (w *Workflow) Unsubscribe(ch <-chan Event) {
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	if sub, ok := w.subs[ch]; ok {
+		delete(w.subs, ch)
+		atomic.AddInt32(&w.subCount, -1)
+		close(sub)
+	}
+}
+
+// publish send event to subscribers without blocking
+func (w *Workflow) publish(event Event) {
+	if atomic.LoadInt32(&w.subCount) == 0 {
+		return
+	}
+
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	for _, sub := range w.subs {
+		select {
+		case sub <- event:
+		default:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	events := w.Subscribe()
+	slow := w.Subscribe()
+
+	exNew, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, testData{}, toDone)
+	require.NotNil(t, err)
+	exDone, err := w.Apply(ctx, exNew, toDone)
+	require.Nil(t, err)
+
+	require.Equal(t, Event{Transit: toNew, To: newState, Data: exNew}, <-events)
+	require.Equal(t, Event{Transit: toDone, From: newState, To: doneState, Data: exDone}, <-events)
+
+	for i := 0; i < eventBuffer*2; i++ {
+		_, err = w.Apply(ctx, testData{}, toNew)
+		require.Nil(t, err)
+	}
+	require.Len(t, slow, eventBuffer)
+
+	w.Unsubscribe(events)
+	for range events {
+	}
+	_, ok := <-events
+	require.False(t, ok)
+	w.Unsubscribe(events)
+}
@@ -0,0 +1,198 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// anyNode node name for the transition without src
+const anyNode = "*"
+
+// DOT export transitions as graphviz digraph
+// transition without src drawn from the "*" node, transition without static dst skipped
+// defined state labeled by meta label and description, final state drawn by double circle
+func (w *Workflow) DOT() string {
+	var (
+		b        strings.Builder
+		wildcard bool
+		edges    strings.Builder
+	)
+
+	trs := w.staticTransitions()
+	for _, nt := range trs {
+		label := strconv.Quote(nt.Name.String())
+		dst := strconv.Quote(nt.Transition.Dst.String())
+		if len(nt.Transition.Src) == 0 {
+			wildcard = true
+			fmt.Fprintf(&edges, "\t%s -> %s [label=%s];\n", strconv.Quote(anyNode), dst, label)
+		}
+		for _, src := range nt.Transition.Src {
+			fmt.Fprintf(&edges, "\t%s -> %s [label=%s];\n", strconv.Quote(src.String()), dst, label)
+		}
+	}
+
+	b.WriteString("digraph workflow {\n")
+	if wildcard {
+		fmt.Fprintf(&b, "\t%s [label=\"any\", shape=point];\n", strconv.Quote(anyNode))
+	}
+	defined := w.definedStates()
+	for _, state := range w.States() {
+		info, ok := defined[state.String()]
+		if !ok {
+			fmt.Fprintf(&b, "\t%s;\n", strconv.Quote(state.String()))
+			continue
+		}
+
+		attrs := make([]string, 0, 3)
+		if info.meta.Label != "" {
+			attrs = append(attrs, "label="+strconv.Quote(info.meta.Label))
+		}
+		if info.meta.Description != "" {
+			attrs = append(attrs, "tooltip="+strconv.Quote(info.meta.Description))
+		}
+		if info.meta.Final {
+			attrs = append(attrs, "shape=doublecircle")
+		}
+		if len(attrs) == 0 {
+			fmt.Fprintf(&b, "\t%s;\n", strconv.Quote(state.String()))
+			continue
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", strconv.Quote(state.String()), strings.Join(attrs, ", "))
+	}
+	b.WriteString(edges.String())
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Describe get text summary with the line "name: [src...] -> dst (flags)" per transition sorted by name
+// any src written as "*", flags list guard, middleware, dst func, idempotent and disabled when set
+func (w *Workflow) Describe() string {
+	var b strings.Builder
+	for _, nt := range w.Transitions() {
+		tr := nt.Transition
+
+		var src []string
+		switch {
+		case tr.SrcFunc != nil:
+			src = []string{"func"}
+		case len(tr.ExceptSrc) > 0:
+			src = append(src, anyNode, "except")
+			for _, state := range tr.ExceptSrc {
+				src = append(src, state.String())
+			}
+		case tr.anySrc():
+			src = []string{anyNode}
+		default:
+			for _, state := range tr.Src {
+				src = append(src, state.String())
+			}
+		}
+
+		dst := "func"
+		if tr.Dst != nil {
+			dst = tr.Dst.String()
+		}
+
+		var flags []string
+		if tr.Guard != nil {
+			flags = append(flags, "guard")
+		}
+		if len(tr.layers) > 0 {
+			flags = append(flags, "middleware")
+		}
+		if tr.DstFunc != nil {
+			flags = append(flags, "dst func")
+		}
+		if tr.Idempotent {
+			flags = append(flags, "idempotent")
+		}
+		if tr.Disabled {
+			flags = append(flags, "disabled")
+		}
+
+		fmt.Fprintf(&b, "%s: [%s] -> %s", nt.Name, strings.Join(src, " "), dst)
+		if len(flags) > 0 {
+			fmt.Fprintf(&b, " (%s)", strings.Join(flags, ", "))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// Mermaid export transitions as mermaid stateDiagram-v2
+// transition without src drawn from the [*] initial marker, final state drawn to the [*] end marker
+func (w *Workflow) Mermaid() string {
+	var b strings.Builder
+
+	b.WriteString("stateDiagram-v2\n")
+	writeStateMeta(&b, w.States(), w.definedStates())
+	writeStateEdges(&b, w.staticTransitions())
+
+	return b.String()
+}
+
+// PlantUML export transitions as plantuml state diagram
+// transition without src drawn from the [*] start state, final state drawn to the [*] end state
+// state names with spaces, dots or other symbols written by sanitized id and declared by the name
+func (w *Workflow) PlantUML() string {
+	var b strings.Builder
+
+	b.WriteString("@startuml\n")
+	writeStateMeta(&b, w.States(), w.definedStates())
+	writeStateEdges(&b, w.staticTransitions())
+	b.WriteString("@enduml\n")
+
+	return b.String()
+}
+
+// writeStateMeta write label, description and final marker of the defined states shared by mermaid and plantuml
+// state with the name not valid as diagram id declared by the name as label of the sanitized id
+func writeStateMeta(b *strings.Builder, all []fmt.Stringer, defined map[string]stateInfo) {
+	for _, state := range all {
+		id := diagramID(state.String())
+		info, ok := defined[state.String()]
+		switch {
+		case ok && info.meta.Label != "":
+			fmt.Fprintf(b, "\tstate %s as %s\n", strconv.Quote(info.meta.Label), id)
+		case id != state.String():
+			fmt.Fprintf(b, "\tstate %s as %s\n", strconv.Quote(state.String()), id)
+		}
+		if !ok {
+			continue
+		}
+		if info.meta.Description != "" {
+			fmt.Fprintf(b, "\t%s : %s\n", id, info.meta.Description)
+		}
+		if info.meta.Final {
+			fmt.Fprintf(b, "\t%s --> [*]\n", id)
+		}
+	}
+}
+
+// writeStateEdges write lines "src --> dst : transit" shared by mermaid and plantuml
+func writeStateEdges(b *strings.Builder, trs []NamedTransition) {
+	for _, nt := range trs {
+		dst := diagramID(nt.Transition.Dst.String())
+		if len(nt.Transition.Src) == 0 {
+			fmt.Fprintf(b, "\t[*] --> %s : %s\n", dst, nt.Name)
+		}
+		for _, src := range nt.Transition.Src {
+			fmt.Fprintf(b, "\t%s --> %s : %s\n", diagramID(src.String()), dst, nt.Name)
+		}
+	}
+}
+
+// diagramID get id of the state for mermaid and plantuml, characters other than letters, digits and "_" replaced by "_"
+func diagramID(state string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		return '_'
+	}, state)
+}
@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newExportWorkflow(t *testing.T) *Workflow {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	return w
+}
+
+func TestWorkflow_DOT(t *testing.T) {
+	w := newExportWorkflow(t)
+	expected := `digraph workflow {
+	"*" [label="any", shape=point];
+	"cancel";
+	"done";
+	"new";
+	"new" -> "cancel" [label="to cancel"];
+	"done" -> "cancel" [label="to cancel"];
+	"new" -> "done" [label="to done"];
+	"*" -> "new" [label="to new"];
+}
+`
+	require.Equal(t, expected, w.DOT())
+	require.Equal(t, w.DOT(), w.DOT())
+}
+
+func TestWorkflow_Describe(t *testing.T) {
+	w := newExportWorkflow(t)
+	require.Nil(t, w.Add(testTransit("archive"), &Transition{
+		Dst:       testState("archive"),
+		ExceptSrc: []fmt.Stringer{newState},
+		Guard: func(ctx context.Context, data Data) (bool, error) {
+			return true, nil
+		},
+		Disabled: true,
+	}, func(ctx context.Context, data Data, next Process) (Data, error) {
+		return next(ctx, data)
+	}))
+	require.Nil(t, w.Add(testTransit("route"), &Transition{
+		SrcFunc: func(state fmt.Stringer) bool { return true },
+		DstFunc: func(ctx context.Context, data Data) (fmt.Stringer, error) { return doneState, nil },
+	}))
+
+	expected := `archive: [* except new] -> archive (guard, middleware, disabled)
+route: [func] -> func (dst func)
+to cancel: [new done] -> cancel
+to done: [new] -> done
+to new: [*] -> new
+`
+	require.Equal(t, expected, w.Describe())
+	require.Equal(t, w.Describe(), w.Describe())
+}
+
+func TestWorkflow_Mermaid(t *testing.T) {
+	w := newExportWorkflow(t)
+	expected := `stateDiagram-v2
+	new --> cancel : to cancel
+	done --> cancel : to cancel
+	new --> done : to done
+	[*] --> new : to new
+`
+	require.Equal(t, expected, w.Mermaid())
+}
+
+func TestWorkflow_Mermaid_StateID(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	review, pending := testState("in review"), testState("active.pending")
+	require.Nil(t, w.DefineState(pending, StateMeta{Description: "waiting", Final: true}))
+	require.Nil(t, w.Add(testTransit("go"), &Transition{Dst: pending, Src: []fmt.Stringer{review}}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: review}))
+
+	require.Equal(t, `stateDiagram-v2
+	state "active.pending" as active_pending
+	active_pending : waiting
+	active_pending --> [*]
+	state "in review" as in_review
+	in_review --> active_pending : go
+	[*] --> in_review : to new
+`, w.Mermaid())
+}
+
+func TestWorkflow_PlantUML(t *testing.T) {
+	w := newExportWorkflow(t)
+	expected := `@startuml
+	new --> cancel : to cancel
+	done --> cancel : to cancel
+	new --> done : to done
+	[*] --> new : to new
+@enduml
+`
+	require.Equal(t, expected, w.PlantUML())
+}
+
+func TestWorkflow_PlantUML_StateID(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	review, pending := testState("in review"), testState("active.pending")
+	require.Nil(t, w.DefineState(review, StateMeta{Label: "In review"}))
+	require.Nil(t, w.Add(testTransit("go"), &Transition{Dst: pending, Src: []fmt.Stringer{review}}))
+
+	require.Equal(t, `@startuml
+	state "active.pending" as active_pending
+	state "In review" as in_review
+	in_review --> active_pending : go
+@enduml
+`, w.PlantUML())
+}
@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateStatesGo generate go source with State type, constant for each state and States slice
+// constant name is "State" with words of the state name in title case, any not letter or digit separate words
+// so "in_review" and "in review" both become StateInReview and such collision returns error matched by ErrInvalidName
+func (w *Workflow) GenerateStatesGo(pkg string) (string, error) {
+	if !token.IsIdentifier(pkg) {
+		return "", fmt.Errorf("package %q: %w", pkg, ErrInvalidName)
+	}
+
+	var b strings.Builder
+	b.WriteString("// Code generated by workflow. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("// State of the workflow\ntype State string\n\n")
+	b.WriteString("// String name of the state\nfunc (s State) String() string {\n\treturn string(s)\n}\n\n")
+
+	all := w.States()
+	names := make([]string, len(all))
+	used := make(map[string]string, len(all))
+	b.WriteString("// workflow states\nconst (\n")
+	for i, state := range all {
+		name := constName(state.String())
+		if prev, ok := used[name]; ok || name == "State" {
+			return "", fmt.Errorf("state %q and %q const %q: %w", prev, state, name, ErrInvalidName)
+		}
+		used[name], names[i] = state.String(), name
+		fmt.Fprintf(&b, "\t%s State = %s\n", name, strconv.Quote(state.String()))
+	}
+	b.WriteString(")\n\n")
+	fmt.Fprintf(&b, "// States all states of the workflow\nvar States = []State{%s}\n", strings.Join(names, ", "))
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", fmt.Errorf("format: %w", err)
+	}
+
+	return string(src), nil
+}
+
+// constName convert state name to exported identifier
+func constName(state string) string {
+	var b strings.Builder
+	b.WriteString("State")
+	upper := true
+	for _, r := range state {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
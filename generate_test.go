@@ -0,0 +1,50 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_GenerateStatesGo(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{testState("in_review")}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	src, err := w.GenerateStatesGo("order")
+	require.Nil(t, err)
+	require.Equal(t, `// Code generated by workflow. DO NOT EDIT.
+
+package order
+
+// State of the workflow
+type State string
+
+// String name of the state
+func (s State) String() string {
+	return string(s)
+}
+
+// workflow states
+const (
+	StateDone     State = "done"
+	StateInReview State = "in_review"
+	StateNew      State = "new"
+)
+
+// States all states of the workflow
+var States = []State{StateDone, StateInReview, StateNew}
+`, src)
+
+	_, err = w.GenerateStatesGo("my-order")
+	require.True(t, errors.Is(err, ErrInvalidName))
+
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: testState("in review")}))
+	_, err = w.GenerateStatesGo("order")
+	require.EqualError(t, err, `state "in review" and "in_review" const "StateInReview": invalid name`)
+}
@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// GenericProcess set state for the typed data
+type GenericProcess[T Data] func(ctx context.Context, data T) (T, error)
+
+// GenericMiddleware run other logic with the typed data
+type GenericMiddleware[T Data] func(ctx context.Context, data T, next GenericProcess[T]) (T, error)
+
+// GenericApply apply state to the typed data
+type GenericApply[T Data] func(ctx context.Context, data T, dst fmt.Stringer) (T, error)
+
+// NewGeneric create new workflow with the typed data
+func NewGeneric[T Data](apply GenericApply[T], mw ...GenericMiddleware[T]) *Generic[T] {
+	return &Generic[T]{
+		w: NewWorkflow(apply.untyped(), WithMiddleware(untypedMiddleware(mw)...)),
+	}
+}
+
+// Generic workflow wrap Workflow and hand back the typed data
+type Generic[T Data] struct {
+	w *Workflow
+}
+
+// Workflow get base workflow
+func (g *Generic[T]) Workflow() *Workflow {
+	return g.w
+}
+
+// Get transition by data and transit
+func (g *Generic[T]) Get(data T, transit fmt.Stringer) *Transition {
+	return g.w.Get(data, transit)
+}
+
+// Add new transition and custom middleware
+func (g *Generic[T]) Add(name fmt.Stringer, transit *Transition, mw ...GenericMiddleware[T]) error {
+	return g.w.Add(name, transit, untypedMiddleware(mw)...)
+}
+
+// Can check can transit by src data
+func (g *Generic[T]) Can(data T, transit fmt.Stringer) bool {
+	return g.w.Can(data, transit)
+}
+
+// CanCtx check can transit by src data and guard with context
+func (g *Generic[T]) CanCtx(ctx context.Context, data T, transit fmt.Stringer) bool {
+	return g.w.CanCtx(ctx, data, transit)
+}
+
+// Apply transit with middleware and options of the call
+func (g *Generic[T]) Apply(ctx context.Context, data T, transit fmt.Stringer, opts ...ApplyOption) (T, error) {
+	res, err := g.w.Apply(ctx, data, transit, opts...)
+
+	return typed[T](res), err
+}
+
+// untyped convert apply to the base Apply
+func (fn GenericApply[T]) untyped() Apply {
+	return func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		res, err := fn(ctx, typed[T](data), dst)
+
+		return untypedData(res), err
+	}
+}
+
+// untyped convert middleware to the base Middleware
+func (mw GenericMiddleware[T]) untyped() Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		res, err := mw(ctx, typed[T](data), func(ctx context.Context, data T) (T, error) {
+			res, err := next(ctx, untypedData(data))
+
+			return typed[T](res), err
+		})
+
+		return untypedData(res), err
+	}
+}
+
+func untypedMiddleware[T Data](mw []GenericMiddleware[T]) []Middleware {
+	out := make([]Middleware, len(mw))
+	for i, m := range mw {
+		out[i] = m.untyped()
+	}
+
+	return out
+}
+
+// untypedData get T as Data, nil pointer or other nil T converted to nil Data
+func untypedData[T Data](data T) Data {
+	if any(data) == nil {
+		return nil
+	}
+	switch v := reflect.ValueOf(data); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	return data
+}
+
+// typed get data as T or zero value when data has other type
+func typed[T Data](data Data) T {
+	t, _ := data.(T)
+
+	return t
+}
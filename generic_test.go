@@ -0,0 +1,74 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneric_Apply(t *testing.T) {
+	ctx := context.Background()
+	w := NewGeneric(func(ctx context.Context, data testData, dst fmt.Stringer) (testData, error) {
+		data.state = dst
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	data := testData{}
+	require.True(t, w.Can(data, toNew))
+	require.False(t, w.Can(data, toDone))
+	require.Nil(t, w.Get(data, toDone))
+
+	ex, err := w.Apply(ctx, data, toDone)
+	require.EqualError(t, err, `transit "to done" from "": transit not allowed`)
+	require.Equal(t, testData{}, ex)
+
+	exNew, err := w.Apply(ctx, data, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, exNew.state)
+}
+
+func TestGeneric_Apply_Middleware(t *testing.T) {
+	ctx := context.Background()
+	var ex []string
+	mw := func(name string) GenericMiddleware[testData] {
+		return func(ctx context.Context, data testData, next GenericProcess[testData]) (testData, error) {
+			ex = append(ex, name)
+			return next(ctx, data)
+		}
+	}
+	w := NewGeneric(func(ctx context.Context, data testData, dst fmt.Stringer) (testData, error) {
+		data.state = dst
+		return data, nil
+	}, mw("global"))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, mw("new")))
+
+	exNew, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, exNew.state)
+	require.Equal(t, []string{"global", "new"}, ex)
+}
+
+func TestGeneric_Apply_PointerError(t *testing.T) {
+	ctx := context.Background()
+	errApply := errors.New("apply")
+	w := NewGeneric(func(ctx context.Context, data *testData, dst fmt.Stringer) (*testData, error) {
+		return nil, errApply
+	}, func(ctx context.Context, data *testData, next GenericProcess[*testData]) (*testData, error) {
+		return next(ctx, data)
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+
+	res, err := w.Workflow().ApplyResult(ctx, &testData{}, toNew)
+	require.True(t, errors.Is(err, errApply))
+	require.Nil(t, res.Data)
+	require.Nil(t, res.To)
+
+	ex, err := w.Apply(ctx, &testData{}, toNew)
+	require.True(t, errors.Is(err, errApply))
+	require.Nil(t, ex)
+}
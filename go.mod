@@ -1,5 +1,15 @@
 module github.com/go-4devs/workflow
 
-go 1.12
+go 1.18
 
-require github.com/stretchr/testify v1.4.0
+require (
+	github.com/stretchr/testify v1.8.1
+	golang.org/x/sync v0.1.0
+	golang.org/x/time v0.3.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
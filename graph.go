@@ -0,0 +1,618 @@
+package workflow
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// States get sorted states used by src and dst of the transitions and states defined by DefineState
+func (w *Workflow) States() []fmt.Stringer {
+	all := states(w.staticTransitions())
+	defined := w.definedStates()
+	if len(defined) == 0 {
+		return all
+	}
+
+	for _, state := range all {
+		delete(defined, state.String())
+	}
+	for _, info := range defined {
+		all = append(all, info.state)
+	}
+	sortStringers(all)
+
+	return all
+}
+
+// staticTransitions get transitions with static dst, transitions with only DstFunc can't be analyzed
+// src with AnyState replaced by empty src, groups replaced by their states
+// except src and src func expanded to matched known states
+func (w *Workflow) staticTransitions() []NamedTransition {
+	trs := w.Transitions()
+	out := trs[:0]
+	for _, nt := range trs {
+		if nt.Transition.Dst == nil {
+			continue
+		}
+		switch {
+		case len(nt.Transition.Src) > 0 && nt.Transition.anySrc():
+			nt.Transition = nt.Transition.clone()
+			nt.Transition.Src = nil
+		case hasGroup(nt.Transition.Src):
+			nt.Transition = nt.Transition.clone()
+			nt.Transition.Src = flattenStates(nt.Transition.Src)
+		}
+		out = append(out, nt)
+	}
+
+	return expandSrc(out)
+}
+
+// expandSrc replace except src and src func by matched known states, transition without such states dropped
+func expandSrc(trs []NamedTransition) []NamedTransition {
+	known := states(trs)
+	out := make([]NamedTransition, 0, len(trs))
+	for _, nt := range trs {
+		if len(nt.Transition.ExceptSrc) == 0 && nt.Transition.SrcFunc == nil {
+			out = append(out, nt)
+			continue
+		}
+
+		tr := nt.Transition.clone()
+		tr.ExceptSrc, tr.SrcFunc = nil, nil
+		for _, state := range known {
+			if matchSrc(nt.Transition, state) {
+				tr.Src = append(tr.Src, state)
+			}
+		}
+		if len(tr.Src) > 0 {
+			out = append(out, NamedTransition{Name: nt.Name, Transition: tr})
+		}
+	}
+
+	return out
+}
+
+// matchSrc check state by src func, except src or src names
+func matchSrc(tr *Transition, state fmt.Stringer) bool {
+	switch {
+	case tr.SrcFunc != nil:
+		return tr.SrcFunc(state)
+	case len(tr.ExceptSrc) > 0:
+		return !containsState(tr.ExceptSrc, state)
+	case tr.anySrc():
+		return true
+	}
+
+	return containsState(flattenStates(tr.Src), state)
+}
+
+// containsState check the list has state with the same name
+func containsState(list []fmt.Stringer, state fmt.Stringer) bool {
+	for _, s := range list {
+		if s.String() == state.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// states collect unique states of the transitions
+func states(trs []NamedTransition) []fmt.Stringer {
+	uniq := make(map[string]fmt.Stringer)
+	for _, nt := range trs {
+		for _, src := range nt.Transition.Src {
+			uniq[src.String()] = src
+		}
+		uniq[nt.Transition.Dst.String()] = nt.Transition.Dst
+	}
+
+	out := make([]fmt.Stringer, 0, len(uniq))
+	for _, state := range uniq {
+		out = append(out, state)
+	}
+	sortStringers(out)
+
+	return out
+}
+
+// Terminal get sorted states without outgoing transitions
+// computed by static src only, guards are not checked and transition without src leave any state
+func (w *Workflow) Terminal() []fmt.Stringer {
+	trs := w.staticTransitions()
+	outgoing := make(map[string]bool)
+	for _, nt := range trs {
+		if len(nt.Transition.Src) == 0 {
+			return []fmt.Stringer{}
+		}
+		for _, src := range nt.Transition.Src {
+			outgoing[src.String()] = true
+		}
+	}
+
+	out := make([]fmt.Stringer, 0)
+	for _, state := range states(trs) {
+		if !outgoing[state.String()] {
+			out = append(out, state)
+		}
+	}
+
+	return out
+}
+
+// Unreachable get sorted states which can't be reached from the initial state and the configured initial states
+// transition without src is reachable from any state
+func (w *Workflow) Unreachable(initial fmt.Stringer) []fmt.Stringer {
+	trs := w.staticTransitions()
+	visited := reachable(trs, append([]fmt.Stringer{initial}, w.initials...)...)
+
+	out := make([]fmt.Stringer, 0)
+	for _, state := range states(trs) {
+		if !visited[state.String()] {
+			out = append(out, state)
+		}
+	}
+
+	return out
+}
+
+// reachable walk transitions from the initial states and collect visited states
+func reachable(trs []NamedTransition, initials ...fmt.Stringer) map[string]bool {
+	next, wildcard := adjacency(trs)
+	visited := make(map[string]bool, len(initials))
+	queue := make([]fmt.Stringer, 0, len(initials))
+	for _, initial := range initials {
+		if initial != nil && !visited[initial.String()] {
+			visited[initial.String()] = true
+			queue = append(queue, initial)
+		}
+	}
+	for _, nt := range wildcard {
+		queue = append(queue, nt.Transition.Dst)
+		visited[nt.Transition.Dst.String()] = true
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, nt := range next[state.String()] {
+			if dst := nt.Transition.Dst; !visited[dst.String()] {
+				visited[dst.String()] = true
+				queue = append(queue, dst)
+			}
+		}
+	}
+
+	return visited
+}
+
+// adjacency group transitions by src and return transitions without src separately
+func adjacency(trs []NamedTransition) (map[string][]NamedTransition, []NamedTransition) {
+	next := make(map[string][]NamedTransition)
+	var wildcard []NamedTransition
+	for _, nt := range trs {
+		if len(nt.Transition.Src) == 0 {
+			wildcard = append(wildcard, nt)
+		}
+		for _, src := range nt.Transition.Src {
+			next[src.String()] = append(next[src.String()], nt)
+		}
+	}
+
+	return next, wildcard
+}
+
+// Validate check transitions and graph and aggregate problems to MultiError, each problem matched by ErrInvalidWorkflow
+//   - transition must have dst or DstFunc and not nil src
+//   - states must be reachable from roots, initial states and states without incoming transitions
+//   - terminal state must be reachable from any state when workflow has terminal states
+func (w *Workflow) Validate() error {
+	trs := w.Transitions()
+
+	var problems []string
+	for _, nt := range trs {
+		if nt.Transition.Dst == nil && nt.Transition.DstFunc == nil {
+			problems = append(problems, fmt.Sprintf("transit %q: empty dst", nt.Name))
+		}
+		for _, src := range nt.Transition.Src {
+			if src == nil {
+				problems = append(problems, fmt.Sprintf("transit %q: empty src", nt.Name))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		problems = validateGraph(w.staticTransitions(), w.initials)
+	}
+
+	if len(problems) > 0 {
+		errs := make(MultiError, len(problems))
+		for i, problem := range problems {
+			errs[i] = invalidError(problem)
+		}
+
+		return fmt.Errorf("%v: %w", ErrInvalidWorkflow, errs)
+	}
+
+	return nil
+}
+
+// validateGraph find states unreachable from roots and states which never reach terminal state
+func validateGraph(trs []NamedTransition, initials []fmt.Stringer) []string {
+	var problems []string
+
+	targeted := make(map[string]bool)
+	for _, nt := range trs {
+		targeted[nt.Transition.Dst.String()] = true
+	}
+
+	all := states(trs)
+	roots := append([]fmt.Stringer(nil), initials...)
+	for _, state := range all {
+		if !targeted[state.String()] {
+			roots = append(roots, state)
+		}
+	}
+	if visited := reachable(trs, roots...); len(roots) > 0 {
+		for _, state := range all {
+			if !visited[state.String()] {
+				problems = append(problems, fmt.Sprintf("unreachable state %q", state))
+			}
+		}
+	}
+
+	finishing := finishing(trs)
+	if len(finishing) > 0 {
+		for _, state := range all {
+			if !finishing[state.String()] {
+				problems = append(problems, fmt.Sprintf("state %q never reach terminal state", state))
+			}
+		}
+	}
+
+	return problems
+}
+
+// finishing collect states from which terminal state is reachable, empty when workflow has no terminal states
+func finishing(trs []NamedTransition) map[string]bool {
+	next, wildcard := adjacency(trs)
+	if len(wildcard) > 0 {
+		return nil
+	}
+
+	prev := make(map[string][]fmt.Stringer)
+	for _, nt := range trs {
+		for _, src := range nt.Transition.Src {
+			prev[nt.Transition.Dst.String()] = append(prev[nt.Transition.Dst.String()], src)
+		}
+	}
+
+	visited := make(map[string]bool)
+	var queue []fmt.Stringer
+	for _, state := range states(trs) {
+		if len(next[state.String()]) == 0 {
+			visited[state.String()] = true
+			queue = append(queue, state)
+		}
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, src := range prev[state.String()] {
+			if !visited[src.String()] {
+				visited[src.String()] = true
+				queue = append(queue, src)
+			}
+		}
+	}
+
+	return visited
+}
+
+// Path get the shortest sequence of transit names from one state to other
+// transition without src can be applied from any state
+func (w *Workflow) Path(from, to fmt.Stringer) ([]fmt.Stringer, error) {
+	next, wildcard := adjacency(w.staticTransitions())
+
+	type step struct {
+		state   fmt.Stringer
+		transit fmt.Stringer
+		prev    *step
+	}
+
+	visited := map[string]bool{from.String(): true}
+	queue := []*step{{state: from}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur.state.String() == to.String() {
+			path := make([]fmt.Stringer, 0)
+			for s := cur; s.prev != nil; s = s.prev {
+				path = append([]fmt.Stringer{s.transit}, path...)
+			}
+
+			return path, nil
+		}
+
+		for _, nt := range append(next[cur.state.String()], wildcard...) {
+			if dst := nt.Transition.Dst; !visited[dst.String()] {
+				visited[dst.String()] = true
+				queue = append(queue, &step{state: dst, transit: nt.Name, prev: cur})
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("from %q to %q: %w", from, to, ErrPathNotFound)
+}
+
+// CheapestPath get the sequence of transit names with the least total weight from one state to other and the weight
+// transition without src can be applied from any state, weight less than 1 counted as 1 so by default it is the shortest path
+func (w *Workflow) CheapestPath(from, to fmt.Stringer) ([]fmt.Stringer, int, error) {
+	next, wildcard := adjacency(w.staticTransitions())
+
+	cost := map[string]int{from.String(): 0}
+	done := make(map[string]bool)
+	queue := &pathQueue{{state: from}}
+	for seq := 1; queue.Len() > 0; {
+		cur := heap.Pop(queue).(*pathStep)
+		if done[cur.state.String()] {
+			continue
+		}
+		done[cur.state.String()] = true
+
+		if cur.state.String() == to.String() {
+			path := make([]fmt.Stringer, 0)
+			for s := cur; s.prev != nil; s = s.prev {
+				path = append([]fmt.Stringer{s.transit}, path...)
+			}
+
+			return path, cur.cost, nil
+		}
+
+		for _, nt := range append(next[cur.state.String()], wildcard...) {
+			dst := nt.Transition.Dst.String()
+			c := cur.cost + nt.Transition.weight()
+			if prev, ok := cost[dst]; done[dst] || ok && prev <= c {
+				continue
+			}
+			cost[dst] = c
+			heap.Push(queue, &pathStep{state: nt.Transition.Dst, transit: nt.Name, prev: cur, cost: c, seq: seq})
+			seq++
+		}
+	}
+
+	return nil, 0, fmt.Errorf("from %q to %q: %w", from, to, ErrPathNotFound)
+}
+
+// pathStep state reached by the transit with the total weight
+type pathStep struct {
+	state   fmt.Stringer
+	transit fmt.Stringer
+	prev    *pathStep
+	cost    int
+	seq     int
+}
+
+// pathQueue min heap of steps by cost, steps with the same cost in push order
+type pathQueue []*pathStep
+
+func (q pathQueue) Len() int { return len(q) }
+
+func (q pathQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q pathQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *pathQueue) Push(x any) { *q = append(*q, x.(*pathStep)) }
+
+func (q *pathQueue) Pop() any {
+	old := *q
+	step := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return step
+}
+
+// Incoming get sorted transit names with the dst state
+func (w *Workflow) Incoming(state fmt.Stringer) []fmt.Stringer {
+	out := make([]fmt.Stringer, 0)
+	for _, nt := range w.staticTransitions() {
+		if nt.Transition.Dst.String() == state.String() {
+			out = append(out, nt.Name)
+		}
+	}
+
+	return out
+}
+
+// Outgoing get sorted unique transit names with the src state or without src, guards and Disabled ignored
+func (w *Workflow) Outgoing(state fmt.Stringer) []fmt.Stringer {
+	out := make([]fmt.Stringer, 0)
+	for _, nt := range w.Transitions() {
+		if matchSrc(nt.Transition, state) && (len(out) == 0 || out[len(out)-1] != nt.Name) {
+			out = append(out, nt.Name)
+		}
+	}
+
+	return out
+}
+
+// TopoSort get states in topological order, states without dependency ordered by name
+// transitions without src are excluded because they make every state a predecessor, their dst still sorted
+// returns error matched by ErrCycle with the states of a cycle when the graph is cyclic
+func (w *Workflow) TopoSort() ([]fmt.Stringer, error) {
+	trs := w.staticTransitions()
+	all := states(trs)
+	next := successors(trs)
+
+	indegree := make(map[string]int, len(all))
+	for _, dsts := range next {
+		for _, dst := range dsts {
+			indegree[dst.String()]++
+		}
+	}
+
+	out := make([]fmt.Stringer, 0, len(all))
+	var queue []fmt.Stringer
+	for _, state := range all {
+		if indegree[state.String()] == 0 {
+			queue = append(queue, state)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		out = append(out, state)
+
+		var ready []fmt.Stringer
+		for _, dst := range next[state.String()] {
+			indegree[dst.String()]--
+			if indegree[dst.String()] == 0 {
+				ready = append(ready, dst)
+			}
+		}
+		queue = append(queue, ready...)
+		sortStringers(queue)
+	}
+
+	if len(out) < len(all) {
+		return nil, fmt.Errorf("%w %q", ErrCycle, findCycle(next, indegree, all))
+	}
+
+	return out, nil
+}
+
+// successors get sorted unique dst states by src without transitions from any state
+func successors(trs []NamedTransition) map[string][]fmt.Stringer {
+	next := make(map[string][]fmt.Stringer)
+	seen := make(map[[2]string]bool)
+	for _, nt := range trs {
+		dst := nt.Transition.Dst
+		for _, src := range nt.Transition.Src {
+			edge := [2]string{src.String(), dst.String()}
+			if !seen[edge] {
+				seen[edge] = true
+				next[src.String()] = append(next[src.String()], dst)
+			}
+		}
+	}
+	for _, dsts := range next {
+		sortStringers(dsts)
+	}
+
+	return next
+}
+
+// findCycle walk back by not sorted predecessors until a state repeats and return the states of the cycle
+// every not sorted state has not sorted predecessor so the walk always finds a cycle
+func findCycle(next map[string][]fmt.Stringer, indegree map[string]int, all []fmt.Stringer) []fmt.Stringer {
+	var state fmt.Stringer
+	prev := make(map[string][]fmt.Stringer)
+	for _, src := range all {
+		if indegree[src.String()] == 0 {
+			continue
+		}
+		if state == nil {
+			state = src
+		}
+		for _, dst := range next[src.String()] {
+			prev[dst.String()] = append(prev[dst.String()], src)
+		}
+	}
+
+	var path []fmt.Stringer
+	index := make(map[string]int)
+	for {
+		if i, ok := index[state.String()]; ok {
+			cycle := make([]fmt.Stringer, 0, len(path)-i)
+			for j := len(path) - 1; j >= i; j-- {
+				cycle = append(cycle, path[j])
+			}
+
+			return rotateCycle(cycle)
+		}
+		index[state.String()] = len(path)
+		path = append(path, state)
+		state = prev[state.String()][0]
+	}
+}
+
+// rotateCycle start the cycle from the state with the least name
+func rotateCycle(cycle []fmt.Stringer) []fmt.Stringer {
+	first := 0
+	for i, state := range cycle {
+		if state.String() < cycle[first].String() {
+			first = i
+		}
+	}
+
+	return append(cycle[first:len(cycle):len(cycle)], cycle[:first]...)
+}
+
+// Cycles get every elementary cycle of the static graph, each started from the state with the least name
+// cycles sorted by the first state, transitions without src are excluded like in TopoSort
+func (w *Workflow) Cycles() [][]fmt.Stringer {
+	trs := w.staticTransitions()
+	next := successors(trs)
+	out := make([][]fmt.Stringer, 0)
+
+	for _, start := range states(trs) {
+		var (
+			path  []fmt.Stringer
+			visit func(state fmt.Stringer)
+		)
+		onPath := make(map[string]bool)
+		visit = func(state fmt.Stringer) {
+			path = append(path, state)
+			onPath[state.String()] = true
+			for _, dst := range next[state.String()] {
+				switch {
+				case dst.String() == start.String():
+					out = append(out, append([]fmt.Stringer(nil), path...))
+				case dst.String() > start.String() && !onPath[dst.String()]:
+					visit(dst)
+				}
+			}
+			onPath[state.String()] = false
+			path = path[:len(path)-1]
+		}
+		visit(start)
+	}
+
+	return out
+}
+
+// Edge transition from the state of the graph
+type Edge struct {
+	Transit fmt.Stringer
+	To      fmt.Stringer
+}
+
+// Graph get outgoing edges by src state sorted by transit name
+// transitions without src listed under the AnyState key, terminal states not listed
+func (w *Workflow) Graph() map[fmt.Stringer][]Edge {
+	keys := make(map[string]fmt.Stringer)
+	out := make(map[fmt.Stringer][]Edge)
+	for _, nt := range w.staticTransitions() {
+		edge := Edge{Transit: nt.Name, To: nt.Transition.Dst}
+		if len(nt.Transition.Src) == 0 {
+			out[AnyState] = append(out[AnyState], edge)
+		}
+		for _, src := range nt.Transition.Src {
+			key, ok := keys[src.String()]
+			if !ok {
+				key = src
+				keys[src.String()] = src
+			}
+			out[key] = append(out[key], edge)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,259 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_States(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Len(t, w.States(), 0)
+
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState, testState("draft")}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	require.Equal(t, []fmt.Stringer{cancelState, doneState, testState("draft"), newState}, w.States())
+}
+
+func TestWorkflow_Terminal(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Equal(t, []fmt.Stringer{}, w.Terminal())
+
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, testState("draft")}}))
+	require.Equal(t, []fmt.Stringer{cancelState, doneState}, w.Terminal())
+
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Equal(t, []fmt.Stringer{}, w.Terminal())
+}
+
+func TestWorkflow_Unreachable(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(testTransit("to archive"), &Transition{Dst: testState("archive"), Src: []fmt.Stringer{testState("dnoe")}}))
+	require.Equal(t, []fmt.Stringer{testState("archive"), testState("dnoe")}, w.Unreachable(newState))
+	require.Equal(t, []fmt.Stringer{doneState, newState}, w.Unreachable(testState("dnoe")))
+
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Equal(t, []fmt.Stringer{testState("archive"), testState("dnoe")}, w.Unreachable(newState))
+
+	w = NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}, WithInitials(newState, testState("dnoe")))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(testTransit("to archive"), &Transition{Dst: testState("archive"), Src: []fmt.Stringer{testState("dnoe")}}))
+	require.Equal(t, []fmt.Stringer{}, w.Unreachable(newState))
+}
+
+func TestWorkflow_Validate_Initials(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+	loop := NamedTransition{Name: testTransit("to loop"), Transition: &Transition{Dst: testState("loop"), Src: []fmt.Stringer{testState("wait")}}}
+	wait := NamedTransition{Name: testTransit("to wait"), Transition: &Transition{Dst: testState("wait"), Src: []fmt.Stringer{testState("loop")}}}
+
+	w := NewWorkflow(apply, WithInitials(testState("loop")))
+	require.Nil(t, w.Add(loop.Name, loop.Transition))
+	require.Nil(t, w.Add(wait.Name, wait.Transition))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{testState("wait")}}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{testState("draft")}}))
+	require.Nil(t, w.Validate())
+
+	w = NewWorkflow(apply)
+	require.Nil(t, w.Add(loop.Name, loop.Transition.clone()))
+	require.Nil(t, w.Add(wait.Name, wait.Transition.clone()))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{testState("wait")}}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{testState("draft")}}))
+	require.EqualError(t, w.Validate(), `invalid workflow: unreachable state "done"; unreachable state "loop"; unreachable state "wait"`)
+}
+
+func TestWorkflow_Validate(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+	w := NewWorkflow(apply)
+	require.Nil(t, w.Validate())
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{testState("draft")}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+	require.Nil(t, w.Validate())
+
+	require.Nil(t, w.Add(testTransit("to loop"), &Transition{Dst: testState("loop"), Src: []fmt.Stringer{testState("wait")}}))
+	require.Nil(t, w.Add(testTransit("to wait"), &Transition{Dst: testState("wait"), Src: []fmt.Stringer{testState("loop")}}))
+	err := w.Validate()
+	require.True(t, errors.Is(err, ErrInvalidWorkflow))
+	require.EqualError(t, err, `invalid workflow: unreachable state "loop"; unreachable state "wait"; `+
+		`state "loop" never reach terminal state; state "wait" never reach terminal state`)
+	var errs MultiError
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 4)
+	require.True(t, errors.Is(errs[0], ErrInvalidWorkflow))
+
+	w = NewWorkflow(apply)
+	w.set(toNew, []*Transition{{}})
+	require.EqualError(t, w.Validate(), `invalid workflow: transit "to new": empty dst`)
+}
+
+func TestWorkflow_Path(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	shipped := testState("shipped")
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(testTransit("to ship"), &Transition{Dst: shipped, Src: []fmt.Stringer{doneState}}))
+	require.Nil(t, w.Add(testTransit("fast ship"), &Transition{Dst: shipped, Src: []fmt.Stringer{testState("express")}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	path, err := w.Path(newState, shipped)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{toDone, testTransit("to ship")}, path)
+
+	path, err = w.Path(newState, newState)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{}, path)
+
+	_, err = w.Path(cancelState, shipped)
+	require.True(t, errors.Is(err, ErrPathNotFound))
+	require.EqualError(t, err, `from "cancel" to "shipped": path not found`)
+
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	path, err = w.Path(cancelState, shipped)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{toNew, toDone, testTransit("to ship")}, path)
+}
+
+func TestWorkflow_CheapestPath(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	shipped := testState("shipped")
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(testTransit("to ship"), &Transition{Dst: shipped, Src: []fmt.Stringer{doneState}}))
+	require.Nil(t, w.Add(testTransit("fast ship"), &Transition{Dst: shipped, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	path, cost, err := w.CheapestPath(newState, shipped)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{testTransit("fast ship")}, path)
+	require.Equal(t, 1, cost)
+
+	require.Nil(t, w.Replace(testTransit("fast ship"), &Transition{Dst: shipped, Src: []fmt.Stringer{newState}, Weight: 5}))
+	require.Nil(t, w.Replace(testTransit("to ship"), &Transition{Dst: shipped, Src: []fmt.Stringer{doneState}, Weight: 2}))
+	path, cost, err = w.CheapestPath(newState, shipped)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{toDone, testTransit("to ship")}, path)
+	require.Equal(t, 3, cost)
+
+	path, cost, err = w.CheapestPath(newState, newState)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{}, path)
+	require.Equal(t, 0, cost)
+
+	_, _, err = w.CheapestPath(cancelState, shipped)
+	require.True(t, errors.Is(err, ErrPathNotFound))
+	require.EqualError(t, err, `from "cancel" to "shipped": path not found`)
+
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Weight: 10}))
+	path, cost, err = w.CheapestPath(cancelState, shipped)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{toNew, toDone, testTransit("to ship")}, path)
+	require.Equal(t, 13, cost)
+}
+
+func TestWorkflow_Incoming_Outgoing(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+	require.Nil(t, w.Add(testTransit("abort"), &Transition{Dst: cancelState, Src: []fmt.Stringer{doneState}}))
+
+	require.Equal(t, []fmt.Stringer{testTransit("abort"), toCancel}, w.Incoming(cancelState))
+	require.Equal(t, []fmt.Stringer{toNew}, w.Incoming(newState))
+	require.Equal(t, []fmt.Stringer{}, w.Incoming(testState("draft")))
+
+	require.Equal(t, []fmt.Stringer{toCancel, toDone, toNew}, w.Outgoing(newState))
+	require.Equal(t, []fmt.Stringer{testTransit("abort"), toCancel, toNew}, w.Outgoing(doneState))
+	require.Equal(t, []fmt.Stringer{toNew}, w.Outgoing(cancelState))
+}
+
+func TestWorkflow_TopoSort(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	draft, review := testState("draft"), testState("review")
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{draft}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Nil(t, w.Add(testTransit("to review"), &Transition{Dst: review, Src: []fmt.Stringer{draft}}))
+	require.Nil(t, w.Add(testTransit("approve"), &Transition{Dst: newState, Src: []fmt.Stringer{review}}))
+
+	sorted, err := w.TopoSort()
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{cancelState, draft, review, newState, doneState}, sorted)
+
+	require.Nil(t, w.Add(testTransit("reject"), &Transition{Dst: draft, Src: []fmt.Stringer{review}}))
+	_, err = w.TopoSort()
+	require.True(t, errors.Is(err, ErrCycle))
+	require.EqualError(t, err, `cycle ["draft" "review"]`)
+}
+
+func TestWorkflow_Cycles(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	draft, review := testState("draft"), testState("review")
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Equal(t, [][]fmt.Stringer{}, w.Cycles())
+
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{draft, newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Nil(t, w.Add(testTransit("to review"), &Transition{Dst: review, Src: []fmt.Stringer{draft, newState}}))
+	require.Nil(t, w.Add(testTransit("reject"), &Transition{Dst: draft, Src: []fmt.Stringer{review}}))
+	require.Nil(t, w.Add(testTransit("approve"), &Transition{Dst: newState, Src: []fmt.Stringer{review}}))
+
+	require.Equal(t, [][]fmt.Stringer{
+		{draft, newState, review},
+		{draft, review},
+		{newState},
+		{newState, review},
+	}, w.Cycles())
+}
+
+func TestWorkflow_Graph(t *testing.T) {
+	w := newExportWorkflow(t)
+	require.Equal(t, map[fmt.Stringer][]Edge{
+		AnyState:  {{Transit: toNew, To: newState}},
+		newState:  {{Transit: toCancel, To: cancelState}, {Transit: toDone, To: doneState}},
+		doneState: {{Transit: toCancel, To: cancelState}},
+	}, w.Graph())
+}
+
+func TestWorkflow_Outgoing_Guard(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}, Guard: func(ctx context.Context, data Data) (bool, error) {
+		return false, nil
+	}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	require.Equal(t, []fmt.Stringer{toCancel, toDone, toNew}, w.Outgoing(newState))
+	require.Equal(t, []fmt.Stringer{toCancel, toNew}, w.Available(testData{state: newState}))
+	require.Equal(t, []fmt.Stringer{toCancel, toNew}, w.Outgoing(doneState))
+	require.Equal(t, []fmt.Stringer{toNew}, w.Outgoing(cancelState))
+}
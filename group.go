@@ -0,0 +1,80 @@
+package workflow
+
+import "fmt"
+
+// StateGroup parent state with child states, group in src match the parent and any child
+type StateGroup struct {
+	Parent   fmt.Stringer
+	Children []fmt.Stringer
+}
+
+// NewStateGroup create group of the parent state, child can be other group
+func NewStateGroup(parent fmt.Stringer, children ...fmt.Stringer) *StateGroup {
+	return &StateGroup{
+		Parent:   parent,
+		Children: children,
+	}
+}
+
+// String name of the parent state
+func (g *StateGroup) String() string {
+	return g.Parent.String()
+}
+
+// Match check state is the parent or child of the group by String()
+func (g *StateGroup) Match(state fmt.Stringer) bool {
+	return g.match(state, stringEqual)
+}
+
+// match check state is the parent or child of the group with the state equality
+func (g *StateGroup) match(state fmt.Stringer, equal StateEqual) bool {
+	if matchState(g.Parent, state, equal) {
+		return true
+	}
+	for _, child := range g.Children {
+		if matchState(child, state, equal) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// States get the parent and all children of the group and nested groups
+func (g *StateGroup) States() []fmt.Stringer {
+	return flattenStates(append([]fmt.Stringer{g.Parent}, g.Children...))
+}
+
+// matchState check state equal to src or member of the src group
+func matchState(src, state fmt.Stringer, equal StateEqual) bool {
+	if g, ok := src.(*StateGroup); ok {
+		return g.match(state, equal)
+	}
+
+	return equal(src, state)
+}
+
+// flattenStates replace groups by their states
+func flattenStates(list []fmt.Stringer) []fmt.Stringer {
+	out := make([]fmt.Stringer, 0, len(list))
+	for _, state := range list {
+		if g, ok := state.(*StateGroup); ok {
+			out = append(out, g.States()...)
+			continue
+		}
+		out = append(out, state)
+	}
+
+	return out
+}
+
+// hasGroup check list contains group
+func hasGroup(list []fmt.Stringer) bool {
+	for _, state := range list {
+		if _, ok := state.(*StateGroup); ok {
+			return true
+		}
+	}
+
+	return false
+}
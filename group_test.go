@@ -0,0 +1,34 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateGroup(t *testing.T) {
+	ctx := context.Background()
+	active, pending, running := testState("active"), testState("active.pending"), testState("active.running")
+	paused := testState("active.running.paused")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	group := NewStateGroup(active, pending, NewStateGroup(running, paused))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: pending}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{group}}))
+
+	require.True(t, w.Can(testData{state: active}, toCancel))
+	require.True(t, w.Can(testData{state: paused}, toCancel))
+	require.False(t, w.Can(testData{state: doneState}, toCancel))
+	ex, err := w.Apply(ctx, testData{state: running}, toCancel)
+	require.Nil(t, err)
+	require.Equal(t, cancelState, ex.GetState())
+
+	require.Equal(t, []fmt.Stringer{active, pending, running, paused}, group.States())
+	require.Equal(t, []fmt.Stringer{toCancel, toNew}, w.Outgoing(paused))
+	require.Equal(t, []fmt.Stringer{active, pending, running, paused, cancelState}, w.States())
+}
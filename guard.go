@@ -0,0 +1,46 @@
+package workflow
+
+import "context"
+
+// And allow when all guards allow, stops on the first rejection or error, no guards allow
+func And(guards ...Guard) Guard {
+	return func(ctx context.Context, data Data) (bool, error) {
+		for _, guard := range guards {
+			ok, err := guard(ctx, data)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+
+		return true, nil
+	}
+}
+
+// Or allow when any guard allow, stops on the first allowance or error, no guards reject
+func Or(guards ...Guard) Guard {
+	return func(ctx context.Context, data Data) (bool, error) {
+		for _, guard := range guards {
+			ok, err := guard(ctx, data)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
+
+// Not invert result of the guard, error returned with rejection
+func Not(guard Guard) Guard {
+	return func(ctx context.Context, data Data) (bool, error) {
+		ok, err := guard(ctx, data)
+		if err != nil {
+			return false, err
+		}
+
+		return !ok, nil
+	}
+}
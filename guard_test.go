@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuards(t *testing.T) {
+	ctx := context.Background()
+	errGuard := errors.New("guard")
+	var calls []string
+	guard := func(name string, ok bool, err error) Guard {
+		return func(ctx context.Context, data Data) (bool, error) {
+			calls = append(calls, name)
+			return ok, err
+		}
+	}
+	check := func(g Guard) (bool, error) {
+		calls = nil
+		return g(ctx, testData{})
+	}
+
+	ok, err := check(And(guard("a", true, nil), guard("b", false, nil), guard("c", true, nil)))
+	require.Nil(t, err)
+	require.False(t, ok)
+	require.Equal(t, []string{"a", "b"}, calls)
+
+	ok, err = check(And(guard("a", true, errGuard), guard("b", true, nil)))
+	require.True(t, errors.Is(err, errGuard))
+	require.False(t, ok)
+	require.Equal(t, []string{"a"}, calls)
+
+	ok, err = check(Or(guard("a", false, nil), guard("b", true, nil), guard("c", false, nil)))
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Equal(t, []string{"a", "b"}, calls)
+
+	ok, err = check(Or(guard("a", false, errGuard), guard("b", true, nil)))
+	require.True(t, errors.Is(err, errGuard))
+	require.False(t, ok)
+
+	ok, err = check(Not(guard("a", false, nil)))
+	require.Nil(t, err)
+	require.True(t, ok)
+
+	ok, err = check(Not(guard("a", false, errGuard)))
+	require.True(t, errors.Is(err, errGuard))
+	require.False(t, ok)
+
+	ok, _ = check(And())
+	require.True(t, ok)
+	ok, _ = check(Or())
+	require.False(t, ok)
+
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{
+		Dst:   doneState,
+		Guard: And(guard("a", true, nil), Not(Or(guard("b", false, nil), guard("c", true, nil)))),
+	}))
+	require.False(t, w.Can(testData{}, toDone))
+}
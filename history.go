@@ -0,0 +1,34 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// HistoryEntry applied transit with states by name, safe to serialize
+type HistoryEntry struct {
+	Transit string    `json:"transit"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Time    time.Time `json:"time"`
+}
+
+// History of the applied transits in apply order
+type History []HistoryEntry
+
+// ApplyTracked apply transit and append entry to the history on success
+// history returned unchanged when apply failed
+func (w *Workflow) ApplyTracked(ctx context.Context, data Data, transit fmt.Stringer, history History) (Data, History, error) {
+	res, err := w.ApplyResult(ctx, data, transit)
+	if err != nil {
+		return res.Data, history, err
+	}
+
+	return res.Data, append(history, HistoryEntry{
+		Transit: transit.String(),
+		From:    stateKey(res.From),
+		To:      stateKey(res.To),
+		Time:    time.Now(),
+	}), nil
+}
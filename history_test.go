@@ -0,0 +1,40 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_ApplyTracked(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	data, history, err := w.ApplyTracked(ctx, testData{}, toNew, nil)
+	require.Nil(t, err)
+	data, history, err = w.ApplyTracked(ctx, data, toDone, history)
+	require.Nil(t, err)
+	require.Equal(t, doneState, data.GetState())
+
+	_, history, err = w.ApplyTracked(ctx, data, toDone, history)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	require.Len(t, history, 2)
+	require.Equal(t, HistoryEntry{Transit: "to new", From: "", To: "new", Time: history[0].Time}, history[0])
+	require.Equal(t, HistoryEntry{Transit: "to done", From: "new", To: "done", Time: history[1].Time}, history[1])
+
+	raw, err := json.Marshal(history)
+	require.Nil(t, err)
+	var decoded History
+	require.Nil(t, json.Unmarshal(raw, &decoded))
+	require.Equal(t, "done", decoded[1].To)
+}
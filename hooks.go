@@ -0,0 +1,243 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Hook run for the data on the state change
+type Hook func(ctx context.Context, data Data) error
+
+// BeforeHook run once before apply, error stops the transit
+type BeforeHook func(ctx context.Context, data Data, transit fmt.Stringer) error
+
+// AfterHook run once after apply with result data or source data when apply failed
+type AfterHook func(ctx context.Context, data Data, transit fmt.Stringer, err error)
+
+// ErrorHook run once when apply return error, transit is nil when ApplyByState resolve no single transit
+type ErrorHook func(ctx context.Context, data Data, transit fmt.Stringer, err error)
+
+// SuccessHook run once after apply succeeded with result data and the states before and after
+type SuccessHook func(ctx context.Context, data Data, transit, from, to fmt.Stringer)
+
+// Before add hook run before global middleware in registration order
+func (w *Workflow) Before(hook BeforeHook) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	w.before = append(w.before, hook)
+	atomic.AddInt32(&w.hooks, 1)
+
+	return nil
+}
+
+// After add hook run after apply in registration order
+func (w *Workflow) After(hook AfterHook) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	w.after = append(w.after, hook)
+	atomic.AddInt32(&w.hooks, 1)
+
+	return nil
+}
+
+// OnError add hook run in registration order for any error returned by apply, the error still returned to the caller
+func (w *Workflow) OnError(hook ErrorHook) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	w.onError = append(w.onError, hook)
+	atomic.AddInt32(&w.hooks, 1)
+
+	return nil
+}
+
+// OnSuccess add hook run in registration order after apply persisted the new state
+func (w *Workflow) OnSuccess(hook SuccessHook) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	w.onSuccess = append(w.onSuccess, hook)
+	atomic.AddInt32(&w.hooks, 1)
+
+	return nil
+}
+
+func runBefore(ctx context.Context, data Data, transit fmt.Stringer, hooks []BeforeHook) error {
+	for _, hook := range hooks {
+		if err := hook(ctx, data, transit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func runAfter(ctx context.Context, data, res Data, transit fmt.Stringer, err error, hooks []AfterHook) {
+	if err == nil {
+		data = res
+	}
+	for _, hook := range hooks {
+		hook(ctx, data, transit, err)
+	}
+}
+
+// runError run error hooks with source data
+func (w *Workflow) runError(ctx context.Context, data Data, transit fmt.Stringer, err error) {
+	if atomic.LoadInt32(&w.hooks) == 0 {
+		return
+	}
+
+	for _, hook := range w.errorHooks() {
+		hook(ctx, data, transit, err)
+	}
+}
+
+// runSuccess run success hooks with the applied result
+func (w *Workflow) runSuccess(ctx context.Context, res Result) {
+	if atomic.LoadInt32(&w.hooks) == 0 {
+		return
+	}
+
+	for _, hook := range w.successHooks() {
+		hook(ctx, res.Data, res.Transit, res.From, res.To)
+	}
+}
+
+// errorHooks get registered error hooks
+func (w *Workflow) errorHooks() []ErrorHook {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	return w.onError
+}
+
+// successHooks get registered success hooks
+func (w *Workflow) successHooks() []SuccessHook {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	return w.onSuccess
+}
+
+// applyHooks get registered before and after hooks
+func (w *Workflow) applyHooks() ([]BeforeHook, []AfterHook) {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	return w.before, w.after
+}
+
+// stateHooks get leave hooks of the src state and enter hooks of the dst state
+func (w *Workflow) stateHooks(src, dst fmt.Stringer) ([]Hook, []Hook) {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	return w.leave[stateKey(src)], w.enter[stateKey(dst)]
+}
+
+// OnEnter add hook run when data enter the state
+// hook run before apply so an error stops the transition and the state not persisted
+func (w *Workflow) OnEnter(state fmt.Stringer, hook Hook) error {
+	return w.addStateHook(&w.enter, state, hook)
+}
+
+// OnLeave add hook run when data leave the state, before enter hooks of the destination
+func (w *Workflow) OnLeave(state fmt.Stringer, hook Hook) error {
+	return w.addStateHook(&w.leave, state, hook)
+}
+
+func (w *Workflow) addStateHook(hooks *map[string][]Hook, state fmt.Stringer, hook Hook) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	if *hooks == nil {
+		*hooks = make(map[string][]Hook)
+	}
+	(*hooks)[stateKey(state)] = append((*hooks)[stateKey(state)], hook)
+	atomic.AddInt32(&w.hooks, 1)
+
+	return nil
+}
+
+// transit run leave hooks of the current state, enter hooks of dst and then apply dst with the timeout
+func (w *Workflow) transit(ctx context.Context, data Data, dst fmt.Stringer, timeout time.Duration) (Data, error) {
+	if atomic.LoadInt32(&w.hooks) == 0 {
+		return w.applyTimeout(ctx, data, dst, timeout)
+	}
+
+	leave, enter := w.stateHooks(data.GetState(), dst)
+	for _, hook := range leave {
+		if err := hook(ctx, data); err != nil {
+			return nil, err
+		}
+	}
+	for _, hook := range enter {
+		if err := hook(ctx, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return w.applyTimeout(ctx, data, dst, timeout)
+}
+
+// applyTimeout run apply with the deadline, zero or negative timeout ignored, only the deadline error of the apply labeled by the timeout
+func (w *Workflow) applyTimeout(ctx context.Context, data Data, dst fmt.Stringer, timeout time.Duration) (Data, error) {
+	if timeout <= 0 {
+		return w.apply(ctx, data, dst)
+	}
+
+	actx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	res, err := w.apply(actx, data, dst)
+	if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return res, fmt.Errorf("apply timeout %v: %w", timeout, err)
+	}
+
+	return res, err
+}
+
+// stateKey get key of the state, nil state has empty key
+func stateKey(state fmt.Stringer) string {
+	if state == nil {
+		return ""
+	}
+
+	return state.String()
+}
+
+// copyHooks copy hooks by state
+func copyHooks(hooks map[string][]Hook) map[string][]Hook {
+	if hooks == nil {
+		return nil
+	}
+	out := make(map[string][]Hook, len(hooks))
+	for state, h := range hooks {
+		out[state] = append([]Hook(nil), h...)
+	}
+
+	return out
+}
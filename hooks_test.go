@@ -0,0 +1,225 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_OnEnter_OnLeave(t *testing.T) {
+	ctx := context.Background()
+	var (
+		ex       []string
+		persists int
+	)
+	errEnter := errors.New("enter")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		persists++
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	hook := func(name string, err error) Hook {
+		return func(ctx context.Context, data Data) error {
+			ex = append(ex, fmt.Sprintf("%s %v", name, data.GetState()))
+			return err
+		}
+	}
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Nil(t, w.OnEnter(newState, hook("enter new", nil)))
+	require.Nil(t, w.OnLeave(newState, hook("leave new", nil)))
+	require.Nil(t, w.OnEnter(doneState, hook("enter done", nil)))
+	require.Nil(t, w.OnEnter(doneState, hook("enter done 2", nil)))
+	require.Nil(t, w.OnEnter(cancelState, hook("enter cancel", errEnter)))
+
+	exNew, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, exNew, toDone)
+	require.Nil(t, err)
+	require.Equal(t, 2, persists)
+	require.Equal(t, []string{"enter new <nil>", "leave new new", "enter done new", "enter done 2 new"}, ex)
+
+	_, err = w.Apply(ctx, exNew, toCancel)
+	require.True(t, errors.Is(err, errEnter))
+	require.Equal(t, 2, persists)
+
+	w.Freeze()
+	require.True(t, errors.Is(w.OnEnter(newState, hook("frozen", nil)), ErrFrozen))
+}
+
+func TestWorkflow_Before_After(t *testing.T) {
+	ctx := context.Background()
+	var ex []string
+	errVeto := errors.New("veto")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, WithMiddleware(func(ctx context.Context, data Data, next Process) (Data, error) {
+		ex = append(ex, "middleware")
+		return next(ctx, data)
+	}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Nil(t, w.Before(func(ctx context.Context, data Data, transit fmt.Stringer) error {
+		ex = append(ex, "before 1 "+transit.String())
+		return nil
+	}))
+	require.Nil(t, w.Before(func(ctx context.Context, data Data, transit fmt.Stringer) error {
+		ex = append(ex, "before 2 "+transit.String())
+		if transit == toCancel {
+			return errVeto
+		}
+		return nil
+	}))
+	require.Nil(t, w.After(func(ctx context.Context, data Data, transit fmt.Stringer, err error) {
+		ex = append(ex, fmt.Sprintf("after %v %v %v", transit, data.GetState(), err))
+	}))
+
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, []string{"before 1 to new", "before 2 to new", "middleware", "after to new new <nil>"}, ex)
+
+	ex = nil
+	_, err = w.Apply(ctx, testData{state: doneState}, toCancel)
+	require.True(t, errors.Is(err, errVeto))
+	require.Equal(t, []string{"before 1 to cancel", "before 2 to cancel", "after to cancel done veto"}, ex)
+}
+
+func TestWorkflow_OnError(t *testing.T) {
+	ctx := context.Background()
+	var ex []string
+	errApply := errors.New("apply")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		if dst == cancelState {
+			return nil, errApply
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Nil(t, w.OnError(func(ctx context.Context, data Data, transit fmt.Stringer, err error) {
+		ex = append(ex, fmt.Sprintf("%v %v: %v", transit, data.GetState(), err))
+	}))
+
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Len(t, ex, 0)
+
+	_, err = w.Apply(ctx, testData{}, toDone)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	_, err = w.Apply(ctx, testData{state: newState}, toCancel)
+	require.True(t, errors.Is(err, errApply))
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = w.Apply(cancelled, testData{}, toNew)
+	require.True(t, errors.Is(err, context.Canceled))
+
+	require.Equal(t, []string{
+		`to done <nil>: transit "to done" from "": transit not allowed`,
+		`to cancel new: transit "to cancel": apply`,
+		`to new <nil>: context canceled`,
+	}, ex)
+
+	w.Freeze()
+	require.True(t, errors.Is(w.OnError(func(ctx context.Context, data Data, transit fmt.Stringer, err error) {}), ErrFrozen))
+}
+
+func TestWorkflow_OnSuccess(t *testing.T) {
+	ctx := context.Background()
+	var (
+		ex        []string
+		persisted fmt.Stringer
+	)
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		persisted = dst
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.OnSuccess(func(ctx context.Context, data Data, transit, from, to fmt.Stringer) {
+		require.Equal(t, persisted, to)
+		ex = append(ex, fmt.Sprintf("%v %v: %v -> %v", transit, data.GetState(), from, to))
+	}))
+
+	exNew, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, exNew, toDone)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, exNew, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, testData{}, toDone)
+	require.NotNil(t, err)
+
+	require.Equal(t, []string{
+		"to new new: <nil> -> new",
+		"to done done: new -> done",
+		"to new new: new -> new",
+	}, ex)
+
+	w.Freeze()
+	require.True(t, errors.Is(w.OnSuccess(func(ctx context.Context, data Data, transit, from, to fmt.Stringer) {}), ErrFrozen))
+}
+
+func TestTransition_ApplyTimeout(t *testing.T) {
+	ctx := context.Background()
+	errWrite := errors.New("write failed")
+	var slowMW bool
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		switch dst {
+		case doneState:
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case cancelState:
+			<-ctx.Done()
+			return nil, errWrite
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, ApplyTimeout: 10 * time.Millisecond}, func(ctx context.Context, data Data, next Process) (Data, error) {
+		time.Sleep(20 * time.Millisecond)
+		slowMW = true
+		return next(ctx, data)
+	}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, ApplyTimeout: 10 * time.Millisecond}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, ApplyTimeout: 10 * time.Millisecond}))
+	require.Nil(t, w.OnEnter(newState, func(ctx context.Context, data Data) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.True(t, slowMW)
+	require.Equal(t, newState, ex.GetState())
+
+	_, err = w.Apply(ctx, testData{}, toDone)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.EqualError(t, err, `transit "to done": apply timeout 10ms: context deadline exceeded`)
+
+	_, err = w.Apply(ctx, testData{}, toCancel)
+	require.True(t, errors.Is(err, errWrite))
+	require.False(t, errors.Is(err, context.DeadlineExceeded))
+	require.EqualError(t, err, `transit "to cancel": write failed`)
+
+	cctx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(5*time.Millisecond, cancel)
+	_, err = w.Apply(cctx, testData{}, toDone)
+	require.True(t, errors.Is(err, context.Canceled))
+}
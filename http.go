@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// transitParam name of the query or form value with the transit name
+const transitParam = "transit"
+
+// Handler apply transit from the "transit" query or form value to data loaded from the request on POST
+// respond with json {"state": "..."} of the saved data or error with the status:
+//   - 400 when transit empty or not resolved
+//   - 404 when load returns error matched by ErrNotFound
+//   - 403 for ErrForbidden
+//   - 422 when guard rejected the transit or returned error
+//   - 409 when transit not allowed from the state
+//   - 500 with generic body for other load, apply and save errors
+func (w *Workflow) Handler(
+	load func(*http.Request) (Data, error),
+	resolveTransit func(string) fmt.Stringer,
+	save func(Data) error,
+) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.Header().Set("Allow", http.MethodPost)
+			writeJSON(rw, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		name := r.FormValue(transitParam)
+		transit := resolveTransit(name)
+		if name == "" || transit == nil {
+			writeJSON(rw, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("transit %q: %v", name, ErrNotResolved)})
+			return
+		}
+
+		data, err := load(r)
+		if errors.Is(err, ErrNotFound) {
+			writeJSON(rw, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			writeError(rw, http.StatusInternalServerError, err)
+			return
+		}
+
+		res, err := w.Apply(r.Context(), data, transit)
+		if err == nil {
+			err = save(res)
+		}
+		if err != nil {
+			writeError(rw, httpStatus(err), err)
+			return
+		}
+
+		writeJSON(rw, http.StatusOK, map[string]string{"state": stateKey(res.GetState())})
+	})
+}
+
+// httpStatus get response status of the apply error
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrGuardRejected), errors.As(err, new(guardError)):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrTransitNotAllowed):
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+// writeError write error response, internal errors replaced by the status text
+func writeError(rw http.ResponseWriter, status int, err error) {
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = http.StatusText(status)
+	}
+	writeJSON(rw, status, map[string]string{"error": msg})
+}
+
+// writeJSON write response with the status and json body
+func writeJSON(rw http.ResponseWriter, status int, body any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(body)
+}
@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_Handler(t *testing.T) {
+	errNotFound := fmt.Errorf("order: %w", ErrNotFound)
+	orders := map[string]Data{
+		"1": testData{state: newState},
+		"2": testData{state: doneState},
+	}
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Guard: func(ctx context.Context, data Data) (bool, error) {
+		return data.GetState() != doneState, nil
+	}}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Guard: func(ctx context.Context, data Data) (bool, error) {
+		return false, errors.New("guard failed")
+	}}))
+
+	h := w.Handler(func(r *http.Request) (Data, error) {
+		if r.URL.Query().Get("id") == "db" {
+			return nil, errors.New("db: connection refused")
+		}
+		data, ok := orders[r.URL.Query().Get("id")]
+		if !ok {
+			return nil, errNotFound
+		}
+		return data, nil
+	}, testResolve, func(data Data) error {
+		if data.GetState() == cancelState {
+			return errors.New("db: connection refused")
+		}
+		return nil
+	})
+
+	cases := []struct {
+		method string
+		target string
+		status int
+		body   string
+	}{
+		{http.MethodPost, "/?id=1&transit=to+done", http.StatusOK, `{"state":"done"}`},
+		{http.MethodGet, "/?id=1&transit=to+done", http.StatusMethodNotAllowed, `{"error":"method not allowed"}`},
+		{http.MethodPost, "/?id=1&transit=unknown", http.StatusBadRequest, `{"error":"transit \"unknown\": not resolved"}`},
+		{http.MethodPost, "/?id=3&transit=to+done", http.StatusNotFound, `{"error":"order: not found"}`},
+		{http.MethodPost, "/?id=db&transit=to+done", http.StatusInternalServerError, `{"error":"Internal Server Error"}`},
+		{http.MethodPost, "/?id=2&transit=to+done", http.StatusConflict, `{"error":"transit \"to done\" from \"done\": transit not allowed"}`},
+		{http.MethodPost, "/?id=2&transit=to+cancel", http.StatusUnprocessableEntity, `{"error":"transit \"to cancel\" from \"done\": transit not allowed"}`},
+		{http.MethodPost, "/?id=1&transit=to+new", http.StatusUnprocessableEntity, `{"error":"transit \"to new\" guard: guard failed"}`},
+		{http.MethodPost, "/?id=1&transit=to+cancel", http.StatusInternalServerError, `{"error":"Internal Server Error"}`},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(c.method, c.target, nil))
+		require.Equal(t, c.status, rec.Code, c.target)
+		require.JSONEq(t, c.body, rec.Body.String(), c.target)
+	}
+}
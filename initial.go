@@ -0,0 +1,38 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// Factory create new data in the state
+type Factory func(ctx context.Context, state fmt.Stringer) (Data, error)
+
+// New create data in the first initial state by the factory
+func (w *Workflow) New(ctx context.Context) (Data, error) {
+	initial := w.Initial()
+	if initial == nil || w.factory == nil {
+		return nil, ErrNoInitial
+	}
+
+	return w.factory(ctx, initial)
+}
+
+// Initial get the first initial state, nil when not configured
+func (w *Workflow) Initial() fmt.Stringer {
+	if len(w.initials) == 0 {
+		return nil
+	}
+
+	return w.initials[0]
+}
+
+// Initials get copy of the initial states in configured order
+func (w *Workflow) Initials() []fmt.Stringer {
+	return append([]fmt.Stringer(nil), w.initials...)
+}
+
+// IsInitial check the state is any of the initial states
+func (w *Workflow) IsInitial(state fmt.Stringer) bool {
+	return state != nil && containsState(w.initials, state)
+}
@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_New(t *testing.T) {
+	ctx := context.Background()
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+
+	w := NewWorkflow(apply)
+	_, err := w.New(ctx)
+	require.True(t, errors.Is(err, ErrNoInitial))
+	require.Nil(t, w.Initial())
+	require.False(t, w.IsInitial(newState))
+
+	w = NewWorkflow(apply, WithInitial(newState), WithFactory(func(ctx context.Context, state fmt.Stringer) (Data, error) {
+		return testData{state: state}, nil
+	}))
+	data, err := w.New(ctx)
+	require.Nil(t, err)
+	require.Equal(t, testData{state: newState}, data)
+	require.Equal(t, newState, w.Initial())
+	require.True(t, w.IsInitial(newState))
+	require.False(t, w.IsInitial(doneState))
+	require.False(t, w.IsInitial(nil))
+	require.Equal(t, []fmt.Stringer{newState}, w.Initials())
+
+	w = NewWorkflow(apply, WithInitials(doneState, newState), WithFactory(func(ctx context.Context, state fmt.Stringer) (Data, error) {
+		return testData{state: state}, nil
+	}))
+	data, err = w.New(ctx)
+	require.Nil(t, err)
+	require.Equal(t, testData{state: doneState}, data)
+	require.Equal(t, doneState, w.Initial())
+	require.Equal(t, []fmt.Stringer{doneState, newState}, w.Initials())
+	require.True(t, w.IsInitial(newState))
+	require.True(t, w.IsInitial(doneState))
+	require.False(t, w.IsInitial(cancelState))
+	require.Nil(t, NewWorkflow(apply).Initials())
+}
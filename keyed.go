@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+)
+
+// WithKeyedMutex serialize apply of the data with the same key, data with other keys applied in parallel
+// lock held from before hooks to apply and released before after, error and success hooks and events
+// so hooks can apply the next transit to the same data, waiting for the lock respect context cancellation
+func WithKeyedMutex(key func(data Data) string) Option {
+	return option(func(w *Workflow) {
+		w.keyed = &keyedMutex{
+			key:   key,
+			locks: make(map[string]*keyedLock),
+		}
+	})
+}
+
+// keyedMutex locks by key, lock removed when nobody hold or wait it
+type keyedMutex struct {
+	key   func(data Data) string
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// lock the key of the data and return unlock
+func (k *keyedMutex) lock(ctx context.Context, data Data) (func(), error) {
+	key := k.key(data)
+
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{ch: make(chan struct{}, 1)}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	select {
+	case l.ch <- struct{}{}:
+		return func() {
+			<-l.ch
+			k.release(key, l)
+		}, nil
+	case <-ctx.Done():
+		k.release(key, l)
+
+		return nil, ctx.Err()
+	}
+}
+
+// release reference of the lock and remove unused lock
+func (k *keyedMutex) release(key string, l *keyedLock) {
+	k.mu.Lock()
+	l.refs--
+	if l.refs == 0 {
+		delete(k.locks, key)
+	}
+	k.mu.Unlock()
+}
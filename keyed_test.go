@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testEntity struct {
+	testData
+	id string
+}
+
+func TestWithKeyedMutex(t *testing.T) {
+	ctx := context.Background()
+	var running, peak int32
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		cur := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			prev := atomic.LoadInt32(&peak)
+			if cur <= prev || atomic.CompareAndSwapInt32(&peak, prev, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return data, nil
+	}, WithKeyedMutex(func(data Data) string {
+		return data.(testEntity).id
+	}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+
+	apply := func(id string, n int) {
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := w.Apply(ctx, testEntity{id: id}, toNew)
+				require.Nil(t, err)
+			}()
+		}
+		wg.Wait()
+	}
+
+	apply("order-1", 10)
+	require.Equal(t, int32(1), atomic.LoadInt32(&peak))
+	require.Len(t, w.keyed.locks, 0)
+
+	unlock, err := w.keyed.lock(ctx, testEntity{id: "order-1"})
+	require.Nil(t, err)
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = w.Apply(cctx, testEntity{id: "order-1"}, toNew)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	_, err = w.Apply(ctx, testEntity{id: "order-2"}, toNew)
+	require.Nil(t, err)
+	unlock()
+	require.Len(t, w.keyed.locks, 0)
+}
+
+func TestWithKeyedMutex_OnSuccess(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testEntity)
+		d.state = dst
+		return d, nil
+	}, WithKeyedMutex(func(data Data) string {
+		return data.(testEntity).id
+	}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	var next error
+	require.Nil(t, w.OnSuccess(func(ctx context.Context, data Data, transit, from, to fmt.Stringer) {
+		if transit == toNew {
+			_, next = w.Apply(ctx, data, toDone)
+		}
+	}))
+
+	_, err := w.Apply(ctx, testEntity{id: "1"}, toNew)
+	require.Nil(t, err)
+	require.Nil(t, next)
+}
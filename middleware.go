@@ -0,0 +1,331 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Recover convert panic of the next process to error matched by ErrPanic
+// onPanic can be nil, returned error attached to the panic error
+func Recover(onPanic func(ctx context.Context, data Data, r any) error) Middleware {
+	return func(ctx context.Context, data Data, next Process) (res Data, err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			pe := panicError{r: r}
+			if onPanic != nil {
+				pe.err = onPanic(ctx, data, r)
+			}
+			res, err = nil, pe
+		}()
+
+		return next(ctx, data)
+	}
+}
+
+// Timeout run next process with the context deadline, next and apply must respect the context to stop in time
+// error of the next process caused by the deadline wrapped with the duration
+func Timeout(d time.Duration) Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		tctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		res, err := next(tctx, data)
+		if err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+			return res, fmt.Errorf("timeout %v: %w", d, err)
+		}
+
+		return res, err
+	}
+}
+
+// Retry run next process up to attempts times while retryable return true for the error
+// backoff get delay before next attempt, nil backoff retry immediately and nil retryable retry any error
+func Retry(attempts int, backoff func(attempt int) time.Duration, retryable func(error) bool) Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		res, err := next(ctx, data)
+		for attempt := 1; attempt < attempts && err != nil; attempt++ {
+			if retryable != nil && !retryable(err) {
+				return res, err
+			}
+
+			if backoff != nil {
+				timer := time.NewTimer(backoff(attempt))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+
+			res, err = next(ctx, data)
+		}
+
+		return res, err
+	}
+}
+
+// CircuitBreaker open after maxFailures consecutive errors of the next process and return ErrCircuitOpen during cooldown
+// after cooldown a single trial call is allowed, its success close the circuit and its error open it again
+// calls started before the circuit opened don't change it, maxFailures less than 1 counted as 1
+// the middleware keeps the state so one instance shared by all transits where it is used
+func CircuitBreaker(maxFailures int, cooldown time.Duration) Middleware {
+	if maxFailures < 1 {
+		maxFailures = 1
+	}
+	cb := &breaker{maxFailures: maxFailures, cooldown: cooldown}
+
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		ok, trial := cb.allow()
+		if !ok {
+			return nil, ErrCircuitOpen
+		}
+
+		failed := true
+		defer func() {
+			cb.done(trial, failed)
+		}()
+
+		res, err := next(ctx, data)
+		failed = err != nil
+
+		return res, err
+	}
+}
+
+// breaker state of the circuit
+type breaker struct {
+	mu          sync.Mutex
+	maxFailures int
+	cooldown    time.Duration
+	failures    int
+	openedAt    time.Time
+	trial       bool
+}
+
+// allow check circuit closed or start the trial call when cooldown passed, trial reported for the trial call
+func (cb *breaker) allow() (ok, trial bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.failures < cb.maxFailures {
+		return true, false
+	}
+	if cb.trial || time.Since(cb.openedAt) < cb.cooldown {
+		return false, false
+	}
+	cb.trial = true
+
+	return true, true
+}
+
+// done count result of the call and open the circuit when failures reached the max, panic of the call counted as failure
+// only the trial call change the open circuit
+func (cb *breaker) done(trial, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch {
+	case trial:
+		cb.trial = false
+		if !failed {
+			cb.failures = 0
+			return
+		}
+		cb.openedAt = time.Now()
+	case cb.failures >= cb.maxFailures:
+		return
+	case !failed:
+		cb.failures = 0
+	default:
+		cb.failures++
+		if cb.failures >= cb.maxFailures {
+			cb.openedAt = time.Now()
+		}
+	}
+}
+
+// RateLimit wait for the shared limiter before next process
+// returns the context error when the wait cancelled or limiter error when the wait exceed the deadline
+func RateLimit(limiter *rate.Limiter) Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		if err := limiter.Wait(ctx); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+
+			return nil, fmt.Errorf("rate limit: %w", err)
+		}
+
+		return next(ctx, data)
+	}
+}
+
+// Logger log every applied transit with the state before and after, error and duration
+// to is nil when next process return nil data
+func Logger(log func(ctx context.Context, transit, from, to fmt.Stringer, err error, dur time.Duration)) Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		transit, _ := TransitFromContext(ctx)
+		from := data.GetState()
+		start := time.Now()
+
+		res, err := next(ctx, data)
+		log(ctx, transit, from, stateOf(res), err, time.Since(start))
+
+		return res, err
+	}
+}
+
+// Only run mw when predicate return true for the transit name from context, otherwise run next process
+// useful to skip global middleware for some transits
+func Only(predicate func(transit fmt.Stringer) bool, mw Middleware) Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		if transit, ok := TransitFromContext(ctx); ok && predicate(transit) {
+			return mw(ctx, data, next)
+		}
+
+		return next(ctx, data)
+	}
+}
+
+// RequireRole allow next process only when roles from context contain the role, otherwise return error matched by ErrForbidden
+func RequireRole(getRoles func(ctx context.Context) []string, role string) Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		for _, r := range getRoles(ctx) {
+			if r == role {
+				return next(ctx, data)
+			}
+		}
+
+		return nil, fmt.Errorf("role %q: %w", role, ErrForbidden)
+	}
+}
+
+// Named set name of the middleware shown in the Debug trace instead of its index
+// errors returned by the middleware itself are wrapped with the name, errors of the next process returned as is
+// Snapshot shows only names of the registered middleware, see RegisterMiddleware
+func Named(name string, mw Middleware) Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		if t, ok := ctx.Value(debugKey).(*debugTracer); ok {
+			t.rename(name)
+		}
+
+		var nextErr error
+		res, err := mw(ctx, data, func(ctx context.Context, data Data) (Data, error) {
+			res, err := next(ctx, data)
+			nextErr = err
+
+			return res, err
+		})
+		if err != nil && (nextErr == nil || !errors.Is(err, nextErr)) {
+			return res, fmt.Errorf("middleware %q: %w", name, err)
+		}
+
+		return res, err
+	}
+}
+
+// Debug write indented trace of the middleware layers run after it with the transit name and duration of each layer
+// add it as the first global middleware to trace all global and transition middleware
+// trace lines of concurrent applies can interleave in the shared writer
+func Debug(out io.Writer) Middleware {
+	return func(ctx context.Context, data Data, next Process) (Data, error) {
+		transit, _ := TransitFromContext(ctx)
+		t := &debugTracer{out: out, depth: 1}
+		fmt.Fprintf(out, "transit %q from %q\n", stateKey(transit), stateKey(stateOf(data)))
+
+		start := time.Now()
+		res, err := next(context.WithValue(ctx, debugKey, t), data)
+		fmt.Fprintf(out, "transit %q to %q %v: %v\n", stateKey(transit), stateKey(stateOf(res)), time.Since(start), err)
+
+		return res, err
+	}
+}
+
+// debugTracer write enter and exit of the layers of the single apply
+type debugTracer struct {
+	out     io.Writer
+	depth   int
+	pending *debugFrame
+}
+
+// debugFrame layer of the trace, enter line written on the first nested layer or on exit so Named can set the name
+type debugFrame struct {
+	name   string
+	indent string
+}
+
+// layer run middleware and write its enter and exit with the indent by depth
+func (t *debugTracer) layer(ctx context.Context, data Data, name string, mw Middleware, next Process) (Data, error) {
+	t.flush()
+	frame := &debugFrame{name: name, indent: strings.Repeat("  ", t.depth)}
+	t.pending = frame
+
+	t.depth++
+	start := time.Now()
+	res, err := mw(ctx, data, next)
+	t.depth--
+
+	t.flush()
+	fmt.Fprintf(t.out, "%s<- %s %v: %v\n", frame.indent, frame.name, time.Since(start), err)
+
+	return res, err
+}
+
+// rename set name of the layer when its enter line not written yet
+func (t *debugTracer) rename(name string) {
+	if t.pending != nil {
+		t.pending.name = name
+	}
+}
+
+// flush write enter line of the pending layer
+func (t *debugTracer) flush() {
+	if t.pending != nil {
+		fmt.Fprintf(t.out, "%s-> %s\n", t.pending.indent, t.pending.name)
+		t.pending = nil
+	}
+}
+
+// callLayer run middleware of the chain and trace it when Debug tracer in the context
+func callLayer(ctx context.Context, data Data, mw Middleware, index int, next Process) (Data, error) {
+	if t, ok := ctx.Value(debugKey).(*debugTracer); ok {
+		return t.layer(ctx, data, fmt.Sprintf("middleware %d", index), mw, next)
+	}
+
+	return mw(ctx, data, next)
+}
+
+// panicError error recovered from panic
+type panicError struct {
+	r   any
+	err error
+}
+
+func (e panicError) Error() string {
+	if e.err != nil {
+		return fmt.Sprintf("%v: %v: %v", ErrPanic, e.r, e.err)
+	}
+
+	return fmt.Sprintf("%v: %v", ErrPanic, e.r)
+}
+
+func (e panicError) Is(target error) bool {
+	return target == ErrPanic
+}
+
+func (e panicError) Unwrap() error {
+	return e.err
+}
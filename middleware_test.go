@@ -0,0 +1,468 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestRecover(t *testing.T) {
+	ctx := context.Background()
+	errCallback := errors.New("callback")
+	var recovered any
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		panic("apply " + dst.String())
+	}, Recover(func(ctx context.Context, data Data, r any) error {
+		recovered = r
+		return errCallback
+	}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, ex)
+	require.True(t, errors.Is(err, ErrPanic))
+	require.True(t, errors.Is(err, errCallback))
+	require.EqualError(t, err, "panic: apply new: callback")
+	require.Equal(t, "apply new", recovered)
+
+	w = NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, Recover(nil))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, func(ctx context.Context, data Data, next Process) (Data, error) {
+		panic(errors.New("middleware"))
+	}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState}))
+
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, ErrPanic))
+	require.EqualError(t, err, "panic: middleware")
+
+	ex, err = w.Apply(ctx, testData{}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+}
+
+func TestTimeout(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		switch dst {
+		case doneState:
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case cancelState:
+			panic("apply")
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, Timeout(time.Second)))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState}, Timeout(time.Millisecond), Timeout(time.Second)))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}, Recover(nil), Timeout(time.Second)))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+
+	ex, err = w.Apply(ctx, testData{}, toDone)
+	require.Nil(t, ex)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.EqualError(t, err, `timeout 1ms: transit "to done": context deadline exceeded`)
+
+	_, err = w.Apply(ctx, testData{}, toCancel)
+	require.True(t, errors.Is(err, ErrPanic))
+}
+
+func TestRetry_RecoverPanic(t *testing.T) {
+	ctx := context.Background()
+	var calls int
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, Retry(3, nil, nil), Recover(nil), func(ctx context.Context, data Data, next Process) (Data, error) {
+		calls++
+		panic("boom")
+	}))
+
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, ErrPanic))
+	require.Equal(t, 3, calls)
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	errFlaky := errors.New("flaky")
+	errFatal := errors.New("fatal")
+	var calls int
+	fails := 2
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		calls++
+		if dst == cancelState {
+			return nil, errFatal
+		}
+		if calls <= fails {
+			return nil, errFlaky
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	retryable := func(err error) bool {
+		return errors.Is(err, errFlaky)
+	}
+	var delays []int
+	backoff := func(attempt int) time.Duration {
+		delays = append(delays, attempt)
+		return time.Millisecond
+	}
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, Retry(3, backoff, retryable)))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState}, Retry(2, nil, retryable)))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}, Retry(3, nil, retryable)))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+	require.Equal(t, 3, calls)
+	require.Equal(t, []int{1, 2}, delays)
+
+	calls = 0
+	_, err = w.Apply(ctx, testData{}, toDone)
+	require.True(t, errors.Is(err, errFlaky))
+	require.Equal(t, 2, calls)
+
+	calls = 0
+	_, err = w.Apply(ctx, testData{}, toCancel)
+	require.True(t, errors.Is(err, errFatal))
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Retry(3, backoff, nil)(cctx, testData{}, func(ctx context.Context, data Data) (Data, error) {
+		calls++
+		return nil, errFlaky
+	})
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, 1, calls)
+}
+
+func TestLogger(t *testing.T) {
+	ctx := context.Background()
+	var logs []string
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, Logger(func(ctx context.Context, transit, from, to fmt.Stringer, err error, dur time.Duration) {
+		require.True(t, dur >= 0)
+		logs = append(logs, fmt.Sprintf("%v: %v -> %v: %v", transit, from, to, err))
+	}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	exNew, err := w.Apply(ctx, testData{state: testState("draft")}, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, exNew, toDone)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, exNew, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, testData{state: doneState}, toDone)
+	require.NotNil(t, err)
+
+	require.Equal(t, []string{
+		"to new: draft -> new: <nil>",
+		"to done: new -> done: <nil>",
+		"to new: new -> new: <nil>",
+		`to done: done -> <nil>: transit "to done" from "done": transit not allowed`,
+	}, logs)
+}
+
+func TestRequireRole(t *testing.T) {
+	ctx := context.Background()
+	var applied int
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		applied++
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	roles := func(ctx context.Context) []string {
+		roles, _ := ctx.Value(testCtxKey{}).([]string)
+		return roles
+	}
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}, RequireRole(roles, "admin")))
+
+	_, err := w.Apply(context.WithValue(ctx, testCtxKey{}, []string{"user"}), testData{}, toCancel)
+	require.True(t, errors.Is(err, ErrForbidden))
+	require.EqualError(t, err, `role "admin": forbidden`)
+	require.Equal(t, 0, applied)
+
+	ex, err := w.Apply(context.WithValue(ctx, testCtxKey{}, []string{"user", "admin"}), testData{}, toCancel)
+	require.Nil(t, err)
+	require.Equal(t, cancelState, ex.GetState())
+}
+
+func TestOnly(t *testing.T) {
+	ctx := context.Background()
+	var audit []fmt.Stringer
+	heartbeat := testTransit("heartbeat")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, Only(func(transit fmt.Stringer) bool {
+		return transit != heartbeat
+	}, func(ctx context.Context, data Data, next Process) (Data, error) {
+		transit, _ := TransitFromContext(ctx)
+		audit = append(audit, transit)
+		return next(ctx, data)
+	}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(heartbeat, &Transition{Dst: newState, Src: []fmt.Stringer{newState}}))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	ex, err = w.Apply(ctx, ex, heartbeat)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+	require.Equal(t, []fmt.Stringer{toNew}, audit)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	errDown := errors.New("down")
+	var (
+		calls int
+		fail  = true
+	)
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		calls++
+		if fail {
+			return nil, errDown
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, CircuitBreaker(2, 20*time.Millisecond)))
+
+	for i := 0; i < 2; i++ {
+		_, err := w.Apply(ctx, testData{}, toNew)
+		require.True(t, errors.Is(err, errDown))
+	}
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+	require.Equal(t, 2, calls)
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, errDown))
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+	require.Equal(t, 3, calls)
+
+	time.Sleep(30 * time.Millisecond)
+	fail = false
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, 5, calls)
+}
+
+func TestCircuitBreaker_Panic(t *testing.T) {
+	ctx := context.Background()
+	cb := CircuitBreaker(1, 10*time.Millisecond)
+	panicNext := func(ctx context.Context, data Data) (Data, error) {
+		panic("boom")
+	}
+
+	require.Panics(t, func() {
+		_, _ = cb(ctx, testData{}, panicNext)
+	})
+	_, err := cb(ctx, testData{}, panicNext)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+
+	time.Sleep(20 * time.Millisecond)
+	require.Panics(t, func() {
+		_, _ = cb(ctx, testData{}, panicNext)
+	})
+	_, err = cb(ctx, testData{}, panicNext)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+
+	time.Sleep(20 * time.Millisecond)
+	ex, err := cb(ctx, testData{state: newState}, func(ctx context.Context, data Data) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+}
+
+func TestCircuitBreaker_Trial(t *testing.T) {
+	ctx := context.Background()
+	errDown := errors.New("down")
+	cb := CircuitBreaker(1, 10*time.Millisecond)
+	pass := func(ctx context.Context, data Data) (Data, error) {
+		return data, nil
+	}
+	fail := func(ctx context.Context, data Data) (Data, error) {
+		return nil, errDown
+	}
+	slow := func(started, release chan struct{}, next Process) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := cb(ctx, testData{}, func(ctx context.Context, data Data) (Data, error) {
+				close(started)
+				<-release
+				return next(ctx, data)
+			})
+			done <- err
+		}()
+		<-started
+
+		return done
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	stale := slow(started, release, pass)
+	_, err := cb(ctx, testData{}, fail)
+	require.True(t, errors.Is(err, errDown))
+	close(release)
+	require.Nil(t, <-stale)
+	_, err = cb(ctx, testData{}, pass)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+
+	time.Sleep(20 * time.Millisecond)
+	started, release = make(chan struct{}), make(chan struct{})
+	trial := slow(started, release, fail)
+	_, err = cb(ctx, testData{}, pass)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+	close(release)
+	require.True(t, errors.Is(<-trial, errDown))
+	_, err = cb(ctx, testData{}, pass)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+
+	cb = CircuitBreaker(0, time.Hour)
+	_, err = cb(ctx, testData{}, pass)
+	require.Nil(t, err)
+	_, err = cb(ctx, testData{}, pass)
+	require.Nil(t, err)
+	_, err = cb(ctx, testData{}, fail)
+	require.True(t, errors.Is(err, errDown))
+	_, err = cb(ctx, testData{}, pass)
+	require.True(t, errors.Is(err, ErrCircuitOpen))
+}
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+	var calls int32
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		atomic.AddInt32(&calls, 1)
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, RateLimit(rate.NewLimiter(rate.Every(time.Hour), 2))))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := w.Apply(ctx, testData{}, toNew)
+			require.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err := w.Apply(cctx, testData{}, toNew)
+	require.NotNil(t, err)
+
+	cctx, cancel = context.WithCancel(ctx)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err = w.Apply(cctx, testData{}, toNew)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestDebug(t *testing.T) {
+	ctx := context.Background()
+	var out strings.Builder
+	mwf := &testMWFactory{}
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, Debug(&out), mwf.Success(t, "global"))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, mwf.Success(t, "first"), mwf.Success(t, "second")))
+
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	trace := regexp.MustCompile(` [0-9.]+[µnm]?s:`).ReplaceAllString(out.String(), " 1ms:")
+	require.Equal(t, `transit "to new" from ""
+  -> middleware 1
+    -> middleware 0
+      -> middleware 1
+      <- middleware 1 1ms: <nil>
+    <- middleware 0 1ms: <nil>
+  <- middleware 1 1ms: <nil>
+transit "to new" to "new" 1ms: <nil>
+`, trace)
+}
+
+func TestNamed(t *testing.T) {
+	ctx := context.Background()
+	var out strings.Builder
+	mwf := &testMWFactory{}
+	errAudit := errors.New("audit failed")
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}
+	w := NewWorkflow(apply, Debug(&out), Named("global", mwf.Success(t, "global")))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, Named("first", mwf.Success(t, "first")), mwf.Success(t, "second")))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState}, Named("audit", func(ctx context.Context, data Data, next Process) (Data, error) {
+		return nil, errAudit
+	})))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}, Named("pass", mwf.Success(t, "pass")), func(ctx context.Context, data Data, next Process) (Data, error) {
+		return nil, errAudit
+	}))
+
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, []string{"global", "first", "second"}, mwf.ex)
+	trace := regexp.MustCompile(` [0-9.]+[µnm]?s:`).ReplaceAllString(out.String(), " 1ms:")
+	require.Equal(t, `transit "to new" from ""
+  -> global
+    -> first
+      -> middleware 1
+      <- middleware 1 1ms: <nil>
+    <- first 1ms: <nil>
+  <- global 1ms: <nil>
+transit "to new" to "new" 1ms: <nil>
+`, trace)
+
+	_, err = w.Apply(ctx, testData{}, toDone)
+	require.True(t, errors.Is(err, errAudit))
+	require.Equal(t, `middleware "audit": audit failed`, err.Error())
+
+	_, err = w.Apply(ctx, testData{}, toCancel)
+	require.True(t, errors.Is(err, errAudit))
+	require.Equal(t, "audit failed", err.Error())
+}
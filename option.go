@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Option configure workflow
+// Middleware implements Option so NewWorkflow accepts values of Middleware type as global middleware
+type Option interface {
+	configure(w *Workflow)
+}
+
+// option configure workflow by func
+type option func(w *Workflow)
+
+func (o option) configure(w *Workflow) {
+	o(w)
+}
+
+// configure add middleware as global
+func (mw Middleware) configure(w *Workflow) {
+	w.middleware = append(w.middleware, mw)
+}
+
+// WithMiddleware add global middleware
+func WithMiddleware(mw ...Middleware) Option {
+	return option(func(w *Workflow) {
+		w.middleware = append(w.middleware, mw...)
+	})
+}
+
+// WithTransitions add copy of the transitions by name without custom middleware in sorted order of the names
+// transitions checked and chained as by Add after all options, NewWorkflowE return the error of invalid one
+func WithTransitions(transitions map[fmt.Stringer]*Transition) Option {
+	return option(func(w *Workflow) {
+		names := make([]fmt.Stringer, 0, len(transitions))
+		for name := range transitions {
+			names = append(names, name)
+		}
+		sortStringers(names)
+		for _, name := range names {
+			w.pending = append(w.pending, NamedTransition{Name: name, Transition: transitions[name]})
+		}
+	})
+}
+
+// WithLogger add global Logger middleware
+func WithLogger(log func(ctx context.Context, transit, from, to fmt.Stringer, err error, dur time.Duration)) Option {
+	return WithMiddleware(Logger(log))
+}
+
+// WithInitial set state of the new data
+func WithInitial(state fmt.Stringer) Option {
+	return WithInitials(state)
+}
+
+// WithInitials set entry states of the workflow, new data created in the first one
+func WithInitials(states ...fmt.Stringer) Option {
+	return option(func(w *Workflow) {
+		w.initials = append([]fmt.Stringer(nil), states...)
+	})
+}
+
+// WithFactory set factory of the new data
+func WithFactory(factory Factory) Option {
+	return option(func(w *Workflow) {
+		w.factory = factory
+	})
+}
+
+// WithStore set store used by ApplyID
+func WithStore(store Store) Option {
+	return option(func(w *Workflow) {
+		w.store = store
+	})
+}
+
+// WithStateEqual set equality of states used by Can, Get and Apply, nil states equal only to nil
+func WithStateEqual(equal StateEqual) Option {
+	return option(func(w *Workflow) {
+		w.equal = func(a, b fmt.Stringer) bool {
+			if a == nil || b == nil {
+				return a == nil && b == nil
+			}
+
+			return equal(a, b)
+		}
+	})
+}
+
+// WithStrictStates make Add and Replace return error matched by ErrUndefinedState
+// when src, except src or dst of the transition not defined by DefineState or WithDefineState
+func WithStrictStates() Option {
+	return option(func(w *Workflow) {
+		w.strict = true
+	})
+}
+
+// WithDefineState define state with meta like DefineState before transitions added by WithTransitions or Load
+func WithDefineState(state fmt.Stringer, meta StateMeta) Option {
+	return option(func(w *Workflow) {
+		if w.stateMeta == nil {
+			w.stateMeta = make(map[string]stateInfo)
+		}
+		w.stateMeta[stateKey(state)] = stateInfo{state: state, meta: meta}
+	})
+}
+
+// WithCancelCheck check context between middleware layers and before apply
+// done context stops the chain with the context error, by default middleware must check the context itself
+func WithCancelCheck() Option {
+	return option(func(w *Workflow) {
+		w.cancelCheck = true
+	})
+}
+
+// ApplyOption configure single Apply call
+type ApplyOption func(cfg *applyConfig)
+
+// applyConfig options of the Apply call
+type applyConfig struct {
+	timeout  time.Duration
+	after    []AfterHook
+	selector func([]*Transition) *Transition
+}
+
+// WithApplyTimeout set deadline of the Apply call, zero or negative duration ignored
+func WithApplyTimeout(d time.Duration) ApplyOption {
+	return func(cfg *applyConfig) {
+		cfg.timeout = d
+	}
+}
+
+// WithSelector choose transition for ApplyByState when several allowed transitions have the dst
+// selector get the transitions in registration order and must not change them, nil result keeps the ambiguity error
+func WithSelector(selector func([]*Transition) *Transition) ApplyOption {
+	return func(cfg *applyConfig) {
+		cfg.selector = selector
+	}
+}
+
+// WithApplyAfter add hook run for the Apply call after the registered after hooks
+func WithApplyAfter(hook AfterHook) ApplyOption {
+	return func(cfg *applyConfig) {
+		cfg.after = append(cfg.after, hook)
+	}
+}
@@ -0,0 +1,150 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWorkflow_Options(t *testing.T) {
+	ctx := context.Background()
+	mwf := &testMWFactory{}
+	var logs []string
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	},
+		mwf.Success(t, "middleware"),
+		WithMiddleware(mwf.Success(t, "with 1"), mwf.Success(t, "with 2")),
+		WithTransitions(map[fmt.Stringer]*Transition{
+			toNew:  {Dst: newState},
+			toDone: {Dst: doneState, Src: []fmt.Stringer{newState}},
+		}),
+		WithLogger(func(ctx context.Context, transit, from, to fmt.Stringer, err error, dur time.Duration) {
+			logs = append(logs, fmt.Sprintf("%v: %v -> %v", transit, from, to))
+		}),
+	)
+
+	require.Len(t, w.Transitions(), 2)
+	exNew, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, exNew, toDone)
+	require.Nil(t, err)
+	require.Equal(t, []string{"middleware", "with 1", "with 2", "middleware", "with 1", "with 2"}, mwf.ex)
+	require.Equal(t, []string{"to new: <nil> -> new", "to done: new -> done"}, logs)
+}
+
+func TestNewWorkflowMW(t *testing.T) {
+	ctx := context.Background()
+	mwf := &testMWFactory{}
+	mws := []Middleware{mwf.Success(t, "mw 1"), mwf.Success(t, "mw 2")}
+	w := NewWorkflowMW(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, append(mws, func(ctx context.Context, data Data, next Process) (Data, error) {
+		mwf.ex = append(mwf.ex, "literal")
+		return next(ctx, data)
+	})...)
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+	require.Equal(t, []string{"mw 1", "mw 2", "literal"}, mwf.ex)
+}
+
+func TestWithTransitions(t *testing.T) {
+	ctx := context.Background()
+	mwf := &testMWFactory{}
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}
+	done := &Transition{Dst: doneState, Src: []fmt.Stringer{newState}, MiddlewareNames: []string{"audit"}}
+	w, err := NewWorkflowE(apply,
+		WithTransitions(map[fmt.Stringer]*Transition{toDone: done}),
+		WithNamedMiddleware("audit", mwf.Success(t, "audit")),
+	)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, testData{state: newState}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, []string{"audit"}, mwf.ex)
+	require.Nil(t, done.Middleware)
+	require.Nil(t, done.layers)
+
+	_, err = NewWorkflowE(apply, WithTransitions(map[fmt.Stringer]*Transition{toDone: done}))
+	require.True(t, errors.Is(err, ErrNotResolved))
+	require.EqualError(t, err, `transit "to done" middleware "audit": not resolved`)
+
+	_, err = NewWorkflowE(apply, WithTransitions(map[fmt.Stringer]*Transition{
+		toCancel: {Dst: cancelState, Src: []fmt.Stringer{newState}, ExceptSrc: []fmt.Stringer{doneState}},
+	}))
+	require.True(t, errors.Is(err, ErrInvalidTransition))
+
+	require.PanicsWithValue(t, `workflow: transit "to new" without dst: invalid transition`, func() {
+		NewWorkflow(apply, WithTransitions(map[fmt.Stringer]*Transition{toNew: {}}))
+	})
+}
+
+func TestWorkflow_Apply_Options(t *testing.T) {
+	ctx := context.Background()
+	var ex []string
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		if dst == doneState {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState}))
+	require.Nil(t, w.After(func(ctx context.Context, data Data, transit fmt.Stringer, err error) {
+		ex = append(ex, "after")
+	}))
+
+	_, err := w.Apply(ctx, testData{}, toDone, WithApplyTimeout(10*time.Millisecond))
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	ex = nil
+	data, err := w.Apply(ctx, testData{}, toNew, WithApplyTimeout(time.Second), WithApplyAfter(func(ctx context.Context, data Data, transit fmt.Stringer, err error) {
+		ex = append(ex, fmt.Sprintf("call %v %v", transit, data.GetState()))
+	}))
+	require.Nil(t, err)
+	require.Equal(t, newState, data.GetState())
+	require.Equal(t, []string{"after", "call to new new"}, ex)
+
+	ex = nil
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, []string{"after"}, ex)
+}
+
+func TestWithStateEqual(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, WithStateEqual(func(a, b fmt.Stringer) bool {
+		return strings.EqualFold(a.String(), b.String())
+	}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{testState("draft")}, Idempotent: true}))
+
+	require.True(t, w.Can(testData{state: testState("NEW")}, toDone))
+	require.False(t, w.Can(testData{}, toDone))
+	require.True(t, w.Can(testData{state: testState("New")}, toNew))
+	ex, err := w.ApplyByState(ctx, testData{state: testState("New")}, testState("DONE"))
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+}
@@ -0,0 +1,20 @@
+module github.com/go-4devs/workflow/otelworkflow
+
+go 1.18
+
+require (
+	github.com/go-4devs/workflow v0.0.0
+	github.com/stretchr/testify v1.8.1
+	go.opentelemetry.io/otel v1.13.0
+	go.opentelemetry.io/otel/trace v1.13.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sync v0.1.0 // indirect
+	golang.org/x/time v0.3.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/go-4devs/workflow => ../
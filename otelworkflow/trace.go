@@ -0,0 +1,59 @@
+// Package otelworkflow provides OpenTelemetry tracing middleware for the workflow.
+package otelworkflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-4devs/workflow"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// attributes of the span
+const (
+	TransitKey = attribute.Key("workflow.transit")
+	FromKey    = attribute.Key("workflow.from")
+	ToKey      = attribute.Key("workflow.to")
+)
+
+// defaultSpanName used when transit not found in context
+const defaultSpanName = "workflow"
+
+// Trace start span named after the transit with source and destination states as attributes
+func Trace(tracer trace.Tracer) workflow.Middleware {
+	return func(ctx context.Context, data workflow.Data, next workflow.Process) (workflow.Data, error) {
+		name := defaultSpanName
+		attrs := []attribute.KeyValue{FromKey.String(stateString(data.GetState()))}
+		if transit, ok := workflow.TransitFromContext(ctx); ok {
+			name = transit.String()
+			attrs = append(attrs, TransitKey.String(name))
+		}
+
+		ctx, span := tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+		defer span.End()
+
+		res, err := next(ctx, data)
+		if res != nil {
+			span.SetAttributes(ToKey.String(stateString(res.GetState())))
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+
+			return res, err
+		}
+		span.SetStatus(codes.Ok, "")
+
+		return res, nil
+	}
+}
+
+func stateString(state fmt.Stringer) string {
+	if state == nil {
+		return ""
+	}
+
+	return state.String()
+}
@@ -0,0 +1,99 @@
+package otelworkflow_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-4devs/workflow"
+	"github.com/go-4devs/workflow/otelworkflow"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type state string
+
+func (s state) String() string {
+	return string(s)
+}
+
+type data struct {
+	state fmt.Stringer
+}
+
+func (d data) GetState() fmt.Stringer {
+	return d.state
+}
+
+type testSpan struct {
+	trace.Span
+	name   string
+	attrs  []attribute.KeyValue
+	status codes.Code
+	ended  bool
+}
+
+func (s *testSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.attrs = append(s.attrs, kv...)
+}
+
+func (s *testSpan) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+
+func (s *testSpan) RecordError(error, ...trace.EventOption) {}
+
+func (s *testSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	span := &testSpan{
+		Span:  trace.SpanFromContext(ctx),
+		name:  name,
+		attrs: cfg.Attributes(),
+	}
+	t.spans = append(t.spans, span)
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func TestTrace(t *testing.T) {
+	ctx := context.Background()
+	tracer := &testTracer{}
+	w := workflow.NewWorkflow(func(ctx context.Context, d workflow.Data, dst fmt.Stringer) (workflow.Data, error) {
+		if dst == state("fail") {
+			return nil, errors.New("apply")
+		}
+		return data{state: dst}, nil
+	}, otelworkflow.Trace(tracer))
+	require.Nil(t, w.Add(state("to new"), &workflow.Transition{Dst: state("new")}))
+	require.Nil(t, w.Add(state("to fail"), &workflow.Transition{Dst: state("fail")}))
+
+	_, err := w.Apply(ctx, data{state: state("draft")}, state("to new"))
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, data{state: state("draft")}, state("to fail"))
+	require.NotNil(t, err)
+
+	require.Len(t, tracer.spans, 2)
+	require.Equal(t, "to new", tracer.spans[0].name)
+	require.True(t, tracer.spans[0].ended)
+	require.Equal(t, codes.Ok, tracer.spans[0].status)
+	require.Equal(t, []attribute.KeyValue{
+		otelworkflow.FromKey.String("draft"),
+		otelworkflow.TransitKey.String("to new"),
+		otelworkflow.ToKey.String("new"),
+	}, tracer.spans[0].attrs)
+
+	require.Equal(t, "to fail", tracer.spans[1].name)
+	require.True(t, tracer.spans[1].ended)
+	require.Equal(t, codes.Error, tracer.spans[1].status)
+}
@@ -0,0 +1,50 @@
+package workflow
+
+import "fmt"
+
+// RegisterMiddleware add middleware by name to use it in Transition.MiddlewareNames and definition
+// middleware resolved on Add so it must be registered before the transitions which use it
+func (w *Workflow) RegisterMiddleware(name string, mw Middleware) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	w.registerMiddleware(name, mw)
+
+	return nil
+}
+
+// WithNamedMiddleware register middleware by name, see RegisterMiddleware
+func WithNamedMiddleware(name string, mw Middleware) Option {
+	return option(func(w *Workflow) {
+		w.registerMiddleware(name, mw)
+	})
+}
+
+// registerMiddleware add middleware by name, caller must hold the lock
+func (w *Workflow) registerMiddleware(name string, mw Middleware) {
+	if w.registry == nil {
+		w.registry = make(map[string]Middleware)
+	}
+	w.registry[name] = mw
+}
+
+// withNamed get custom middleware followed by the named middleware of the transition, caller must hold the lock
+func (w *Workflow) withNamed(name fmt.Stringer, transit *Transition, mw []Middleware) ([]Middleware, error) {
+	if len(transit.MiddlewareNames) == 0 {
+		return mw, nil
+	}
+
+	out := make([]Middleware, 0, len(mw)+len(transit.MiddlewareNames))
+	out = append(out, mw...)
+	for _, mwName := range transit.MiddlewareNames {
+		named, ok := w.registry[mwName]
+		if !ok {
+			return nil, fmt.Errorf("transit %q middleware %q: %w", name, mwName, ErrNotResolved)
+		}
+		out = append(out, named)
+	}
+
+	return out, nil
+}
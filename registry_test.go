@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_RegisterMiddleware(t *testing.T) {
+	ctx := context.Background()
+	var ex []string
+	mw := func(name string) Middleware {
+		return func(ctx context.Context, data Data, next Process) (Data, error) {
+			ex = append(ex, name)
+			return next(ctx, data)
+		}
+	}
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}
+
+	w := NewWorkflow(apply, WithNamedMiddleware("audit", mw("audit")))
+	require.Nil(t, w.RegisterMiddleware("retry", mw("retry")))
+	require.Nil(t, w.Add(toNew, &Transition{
+		Dst:             newState,
+		MiddlewareNames: []string{"audit", "retry"},
+		Middleware:      mw("transition"),
+	}, mw("custom")))
+	err := w.Add(toDone, &Transition{Dst: doneState, MiddlewareNames: []string{"unknown"}})
+	require.True(t, errors.Is(err, ErrNotResolved))
+	require.EqualError(t, err, `transit "to done" middleware "unknown": not resolved`)
+	require.False(t, w.HasTransition(toDone))
+
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, []string{"custom", "audit", "retry", "transition"}, ex)
+
+	var def Definition
+	require.Nil(t, json.Unmarshal([]byte(`{"transitions":[
+		{"name":"to new","dst":"new","middleware":["audit"]}
+	]}`), &def))
+	_, err = Load(def, testResolve, apply)
+	require.True(t, errors.Is(err, ErrNotResolved))
+
+	ex = nil
+	w, err = Load(def, testResolve, apply, WithNamedMiddleware("audit", mw("audit")))
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, []string{"audit"}, ex)
+
+	data, err := json.Marshal(w)
+	require.Nil(t, err)
+	require.JSONEq(t, `{"transitions":[{"name":"to new","dst":"new","middleware":["audit"]}]}`, string(data))
+
+	w.Freeze()
+	require.True(t, errors.Is(w.RegisterMiddleware("audit", mw("audit")), ErrFrozen))
+}
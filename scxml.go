@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// scxmlNamespace of the W3C SCXML document
+const scxmlNamespace = "http://www.w3.org/2005/07/scxml"
+
+type scxmlDocument struct {
+	XMLName xml.Name     `xml:"scxml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Version string       `xml:"version,attr"`
+	Initial string       `xml:"initial,attr,omitempty"`
+	States  []scxmlState `xml:"state"`
+}
+
+type scxmlState struct {
+	ID          string            `xml:"id,attr"`
+	Transitions []scxmlTransition `xml:"transition"`
+}
+
+type scxmlTransition struct {
+	Event  string `xml:"event,attr"`
+	Target string `xml:"target,attr"`
+}
+
+// SCXML export transitions as W3C SCXML document with a state for each state of the workflow
+// transition without src added to every state, whitespace of the transit name replaced by "_" to keep single event token
+// and characters not allowed in xml ID of the state replaced by "_"
+func (w *Workflow) SCXML() ([]byte, error) {
+	doc := scxmlDocument{
+		Xmlns:   scxmlNamespace,
+		Version: "1.0",
+	}
+
+	graph := w.Graph()
+	outgoing := make(map[string][]Edge, len(graph))
+	for state, edges := range graph {
+		outgoing[state.String()] = edges
+	}
+
+	initial := stateKey(w.Initial())
+	for _, state := range w.States() {
+		if state.String() == initial {
+			doc.Initial = scxmlID(initial)
+		}
+		st := scxmlState{ID: scxmlID(state.String())}
+		for _, edge := range outgoing[state.String()] {
+			st.Transitions = append(st.Transitions, scxmlTransition{Event: scxmlEvent(edge.Transit.String()), Target: scxmlID(edge.To.String())})
+		}
+		for _, edge := range graph[AnyState] {
+			st.Transitions = append(st.Transitions, scxmlTransition{Event: scxmlEvent(edge.Transit.String()), Target: scxmlID(edge.To.String())})
+		}
+		doc.States = append(doc.States, st)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("scxml: %w", err)
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+// scxmlEvent convert transit name to single event token
+func scxmlEvent(name string) string {
+	return strings.Join(strings.Fields(name), "_")
+}
+
+// scxmlID convert state to xml ID, invalid characters replaced by "_" and ID starting with digit or punctuation prefixed by "_"
+func scxmlID(state string) string {
+	id := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' {
+			return r
+		}
+
+		return '_'
+	}, state)
+	if id == "" || !unicode.IsLetter([]rune(id)[0]) && id[0] != '_' {
+		id = "_" + id
+	}
+
+	return id
+}
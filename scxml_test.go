@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_SCXML(t *testing.T) {
+	w := newExportWorkflow(t)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0">
+	<state id="cancel">
+		<transition event="to_new" target="new"></transition>
+	</state>
+	<state id="done">
+		<transition event="to_cancel" target="cancel"></transition>
+		<transition event="to_new" target="new"></transition>
+	</state>
+	<state id="new">
+		<transition event="to_cancel" target="cancel"></transition>
+		<transition event="to_done" target="done"></transition>
+		<transition event="to_new" target="new"></transition>
+	</state>
+</scxml>`
+	out, err := w.SCXML()
+	require.Nil(t, err)
+	require.Equal(t, expected, string(out))
+}
+
+func TestWorkflow_SCXML_Tokens(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}, WithInitial(testState("in review")))
+	require.Nil(t, w.Add(testTransit("send  to\treview"), &Transition{Dst: testState("in review"), Src: []fmt.Stringer{testState("1st draft")}}))
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<scxml xmlns="http://www.w3.org/2005/07/scxml" version="1.0" initial="in_review">
+	<state id="_1st_draft">
+		<transition event="send_to_review" target="in_review"></transition>
+	</state>
+	<state id="in_review"></state>
+</scxml>`
+	out, err := w.SCXML()
+	require.Nil(t, err)
+	require.Equal(t, expected, string(out))
+
+	w = NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}, WithInitial(testState("draft")))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	out, err = w.SCXML()
+	require.Nil(t, err)
+	require.NotContains(t, string(out), "initial=")
+}
@@ -0,0 +1,95 @@
+package workflow
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// StateEqual compare states
+type StateEqual func(a, b fmt.Stringer) bool
+
+// stringEqual compare states by String(), nil states equal only to nil
+func stringEqual(a, b fmt.Stringer) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return a.String() == b.String()
+}
+
+// StateMeta describe state for ui and diagrams
+type StateMeta struct {
+	Label       string `json:"label,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Final state drawn as end state of diagrams
+	Final bool `json:"final,omitempty"`
+}
+
+// stateInfo defined state with meta
+type stateInfo struct {
+	state fmt.Stringer
+	meta  StateMeta
+}
+
+// DefineState set meta of the state, defined state returned by States even without transitions
+func (w *Workflow) DefineState(state fmt.Stringer, meta StateMeta) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	if w.stateMeta == nil {
+		w.stateMeta = make(map[string]stateInfo)
+	}
+	w.stateMeta[stateKey(state)] = stateInfo{state: state, meta: meta}
+
+	return nil
+}
+
+// StateInfo get meta of the defined state
+func (w *Workflow) StateInfo(state fmt.Stringer) (StateMeta, bool) {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+	info, ok := w.stateMeta[stateKey(state)]
+
+	return info.meta, ok
+}
+
+// checkStates check states of the transition defined in strict mode, AnyState always allowed, caller must hold the lock
+func (w *Workflow) checkStates(name fmt.Stringer, transit *Transition) error {
+	if !w.strict {
+		return nil
+	}
+
+	states := append(flattenStates(transit.Src), transit.ExceptSrc...)
+	if transit.Dst != nil {
+		states = append(states, transit.Dst)
+	}
+	for _, state := range states {
+		if state == AnyState {
+			continue
+		}
+		if _, ok := w.stateMeta[stateKey(state)]; !ok {
+			return fmt.Errorf("transit %q state %q: %w", name, state, ErrUndefinedState)
+		}
+	}
+
+	return nil
+}
+
+// definedStates get copy of the defined states by name
+func (w *Workflow) definedStates() map[string]stateInfo {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	out := make(map[string]stateInfo, len(w.stateMeta))
+	for key, info := range w.stateMeta {
+		out[key] = info
+	}
+
+	return out
+}
@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_DefineState(t *testing.T) {
+	w := newExportWorkflow(t)
+	archived := testState("archived")
+	require.Nil(t, w.DefineState(doneState, StateMeta{Label: "Done", Description: "order completed", Final: true}))
+	require.Nil(t, w.DefineState(archived, StateMeta{Label: "Archived"}))
+
+	meta, ok := w.StateInfo(doneState)
+	require.True(t, ok)
+	require.Equal(t, StateMeta{Label: "Done", Description: "order completed", Final: true}, meta)
+	_, ok = w.StateInfo(newState)
+	require.False(t, ok)
+	require.Equal(t, []fmt.Stringer{archived, cancelState, doneState, newState}, w.States())
+
+	require.Equal(t, `digraph workflow {
+	"*" [label="any", shape=point];
+	"archived" [label="Archived"];
+	"cancel";
+	"done" [label="Done", tooltip="order completed", shape=doublecircle];
+	"new";
+	"new" -> "cancel" [label="to cancel"];
+	"done" -> "cancel" [label="to cancel"];
+	"new" -> "done" [label="to done"];
+	"*" -> "new" [label="to new"];
+}
+`, w.DOT())
+	require.Equal(t, `stateDiagram-v2
+	state "Archived" as archived
+	state "Done" as done
+	done : order completed
+	done --> [*]
+	new --> cancel : to cancel
+	done --> cancel : to cancel
+	new --> done : to done
+	[*] --> new : to new
+`, w.Mermaid())
+
+	w.Freeze()
+	require.True(t, errors.Is(w.DefineState(newState, StateMeta{}), ErrFrozen))
+}
+
+func TestWithStrictStates(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+	w := NewWorkflow(apply, WithStrictStates())
+	require.Nil(t, w.DefineState(newState, StateMeta{}))
+	require.Nil(t, w.DefineState(doneState, StateMeta{}))
+
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{AnyState}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	err := w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState}})
+	require.True(t, errors.Is(err, ErrUndefinedState))
+	require.Equal(t, `transit "to cancel" state "cancel": undefined state`, err.Error())
+
+	err = w.Replace(toDone, &Transition{Dst: doneState, ExceptSrc: []fmt.Stringer{cancelState}})
+	require.True(t, errors.Is(err, ErrUndefinedState))
+	err = w.Add(toCancel, &Transition{Dst: doneState, Src: []fmt.Stringer{NewStateGroup(newState, cancelState)}})
+	require.True(t, errors.Is(err, ErrUndefinedState))
+	_, ok := w.Transition(toCancel)
+	require.False(t, ok)
+
+	w = NewWorkflow(apply)
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState}}))
+}
+
+func TestWithDefineState(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+	def := Definition{Transitions: []TransitionDefinition{
+		{Name: "to new", Dst: "new"},
+		{Name: "to done", Dst: "done", Src: []string{"new"}},
+	}}
+
+	w, err := Load(def, testResolve, apply,
+		WithStrictStates(),
+		WithDefineState(newState, StateMeta{}),
+		WithDefineState(doneState, StateMeta{Label: "Done", Final: true}),
+	)
+	require.Nil(t, err)
+	require.Equal(t, []fmt.Stringer{doneState, newState}, w.States())
+	meta, ok := w.StateInfo(doneState)
+	require.True(t, ok)
+	require.Equal(t, StateMeta{Label: "Done", Final: true}, meta)
+
+	_, err = Load(def, testResolve, apply, WithStrictStates(), WithDefineState(newState, StateMeta{}))
+	require.True(t, errors.Is(err, ErrUndefinedState))
+
+	_, err = NewWorkflowE(apply,
+		WithStrictStates(),
+		WithDefineState(newState, StateMeta{}),
+		WithTransitions(map[fmt.Stringer]*Transition{toNew: {Dst: newState}}),
+	)
+	require.Nil(t, err)
+	_, err = NewWorkflowE(apply,
+		WithStrictStates(),
+		WithTransitions(map[fmt.Stringer]*Transition{toNew: {Dst: newState}}),
+	)
+	require.True(t, errors.Is(err, ErrUndefinedState))
+}
+
+func TestWithStrictStates_Merge(t *testing.T) {
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}
+	w := NewWorkflow(apply, WithStrictStates(), WithDefineState(newState, StateMeta{}), WithDefineState(doneState, StateMeta{}))
+	other := NewWorkflow(apply)
+	require.Nil(t, other.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, other.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState}}))
+
+	err := w.Merge(other)
+	require.True(t, errors.Is(err, ErrUndefinedState))
+	require.Empty(t, w.Transitions())
+
+	require.True(t, other.Remove(toCancel))
+	require.Nil(t, w.Merge(other))
+	_, ok := w.Transition(toDone)
+	require.True(t, ok)
+}
@@ -0,0 +1,58 @@
+package workflow
+
+import (
+	"fmt"
+	"sync"
+)
+
+// stats count successfully applied transits
+type stats struct {
+	mu     sync.Mutex
+	counts map[fmt.Stringer]int
+}
+
+// WithStats count successfully applied transits, see Stats
+func WithStats() Option {
+	return option(func(w *Workflow) {
+		w.stats = &stats{counts: make(map[fmt.Stringer]int)}
+	})
+}
+
+// Stats get copy of the applied transit counts since construction or ResetStats
+// empty when workflow created without WithStats
+func (w *Workflow) Stats() map[fmt.Stringer]int {
+	out := make(map[fmt.Stringer]int)
+	if w.stats == nil {
+		return out
+	}
+
+	w.stats.mu.Lock()
+	defer w.stats.mu.Unlock()
+	for transit, count := range w.stats.counts {
+		out[transit] = count
+	}
+
+	return out
+}
+
+// ResetStats clear the applied transit counts
+func (w *Workflow) ResetStats() {
+	if w.stats == nil {
+		return
+	}
+
+	w.stats.mu.Lock()
+	w.stats.counts = make(map[fmt.Stringer]int)
+	w.stats.mu.Unlock()
+}
+
+// count applied transit when stats enabled
+func (w *Workflow) count(transit fmt.Stringer) {
+	if w.stats == nil {
+		return
+	}
+
+	w.stats.mu.Lock()
+	w.stats.counts[transit]++
+	w.stats.mu.Unlock()
+}
@@ -0,0 +1,42 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_Stats(t *testing.T) {
+	ctx := context.Background()
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}
+	w := NewWorkflow(apply, WithStats())
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, ex, toDone)
+	require.Nil(t, err)
+	_, err = w.Apply(ctx, testData{}, toDone)
+	require.NotNil(t, err)
+	require.Equal(t, map[fmt.Stringer]int{toNew: 2, toDone: 1}, w.Stats())
+	require.Equal(t, map[fmt.Stringer]int{}, w.Clone().Stats())
+
+	w.ResetStats()
+	require.Equal(t, map[fmt.Stringer]int{}, w.Stats())
+
+	w = NewWorkflow(apply)
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	w.ResetStats()
+	require.Equal(t, map[fmt.Stringer]int{}, w.Stats())
+}
@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// Store load and persist data
+type Store interface {
+	Load(ctx context.Context, id string) (Data, error)
+	// Save persist data when stored version equals version and return ErrConflict otherwise
+	Save(ctx context.Context, data Data, version int) error
+}
+
+// Versioned data with version for optimistic locking
+type Versioned interface {
+	Data
+	GetVersion() int
+}
+
+// ApplyID load data by id, apply transit and save it with version of the loaded data
+// data without version saved with zero version
+func (w *Workflow) ApplyID(ctx context.Context, id string, transit fmt.Stringer) (Data, error) {
+	if w.store == nil {
+		return nil, ErrNoStore
+	}
+
+	data, err := w.store.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("load %q: %w", id, err)
+	}
+
+	var version int
+	if v, ok := data.(Versioned); ok {
+		version = v.GetVersion()
+	}
+
+	res, err := w.Apply(ctx, data, transit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := w.store.Save(ctx, res, version); err != nil {
+		return nil, fmt.Errorf("save %q: %w", id, err)
+	}
+
+	return res, nil
+}
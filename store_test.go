@@ -0,0 +1,86 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testVersioned struct {
+	id      string
+	state   fmt.Stringer
+	version int
+}
+
+func (d testVersioned) GetState() fmt.Stringer {
+	return d.state
+}
+
+func (d testVersioned) GetVersion() int {
+	return d.version
+}
+
+type testStore struct {
+	mu   sync.Mutex
+	data map[string]testVersioned
+}
+
+func (s *testStore) Load(ctx context.Context, id string) (Data, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.data[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return d, nil
+}
+
+func (s *testStore) Save(ctx context.Context, data Data, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := data.(testVersioned)
+	if s.data[d.id].version != version {
+		return ErrConflict
+	}
+	d.version = version + 1
+	s.data[d.id] = d
+
+	return nil
+}
+
+func TestWorkflow_ApplyID(t *testing.T) {
+	ctx := context.Background()
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testVersioned)
+		d.state = dst
+		return d, nil
+	}
+	store := &testStore{data: map[string]testVersioned{"1": {id: "1", state: newState}}}
+
+	_, err := NewWorkflow(apply).ApplyID(ctx, "1", toDone)
+	require.True(t, errors.Is(err, ErrNoStore))
+
+	w := NewWorkflow(apply, WithStore(store))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}, func(ctx context.Context, data Data, next Process) (Data, error) {
+		store.data["1"] = testVersioned{id: "1", state: doneState, version: 5}
+		return next(ctx, data)
+	}))
+
+	_, err = w.ApplyID(ctx, "2", toDone)
+	require.EqualError(t, err, `load "2": not found`)
+
+	res, err := w.ApplyID(ctx, "1", toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, res.GetState())
+	require.Equal(t, testVersioned{id: "1", state: doneState, version: 1}, store.data["1"])
+
+	_, err = w.ApplyID(ctx, "1", toCancel)
+	require.True(t, errors.Is(err, ErrConflict))
+	require.Equal(t, doneState, store.data["1"].state)
+}
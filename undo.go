@@ -0,0 +1,16 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+)
+
+// Undo apply the inverse transit of the applied result to its data
+// result of the undo can be undone again when the inverse transition has own Inverse
+func (w *Workflow) Undo(ctx context.Context, res Result) (Result, error) {
+	if res.Transition == nil || res.Transition.Inverse == nil {
+		return Result{Data: res.Data, From: res.To}, fmt.Errorf("transit %q: %w", res.Transit, ErrNoInverse)
+	}
+
+	return w.ApplyResult(ctx, res.Data, res.Transition.Inverse)
+}
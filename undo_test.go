@@ -0,0 +1,43 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow_Undo(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	reopen := testTransit("reopen")
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}, Inverse: reopen}))
+	require.Nil(t, w.Add(reopen, &Transition{Dst: newState, Src: []fmt.Stringer{doneState}, Inverse: toDone}))
+
+	res, err := w.ApplyResult(ctx, testData{state: newState}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, toDone, res.Transit)
+
+	undo, err := w.Undo(ctx, res)
+	require.Nil(t, err)
+	require.Equal(t, reopen, undo.Transit)
+	require.Equal(t, doneState, undo.From)
+	require.Equal(t, newState, undo.Data.GetState())
+
+	redo, err := w.Undo(ctx, undo)
+	require.Nil(t, err)
+	require.Equal(t, doneState, redo.Data.GetState())
+
+	res, err = w.ApplyResult(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	_, err = w.Undo(ctx, res)
+	require.True(t, errors.Is(err, ErrNoInverse))
+	require.EqualError(t, err, `transit "to new": inverse transit not configured`)
+}
@@ -4,16 +4,47 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // base errors
 var (
 	ErrTransitNotAllowed = errors.New("transit not allowed")
 	ErrDuplicateTransit  = errors.New("duplicate transit")
+	ErrTransitNotFound   = errors.New("transit not found")
+	ErrNotResolved       = errors.New("not resolved")
+	ErrFrozen            = errors.New("workflow frozen")
+	ErrPanic             = errors.New("panic")
+	ErrAmbiguousTransit  = errors.New("ambiguous transit")
+	ErrInvalidWorkflow   = errors.New("invalid workflow")
+	ErrPathNotFound      = errors.New("path not found")
+	ErrNoInitial         = errors.New("initial state not configured")
+	ErrNoStore           = errors.New("store not configured")
+	ErrConflict          = errors.New("version conflict")
+	ErrInvalidTransition = errors.New("invalid transition")
+	ErrNoInverse         = errors.New("inverse transit not configured")
+	ErrForbidden         = errors.New("forbidden")
+	ErrCycle             = errors.New("cycle")
+	ErrInvalidName       = errors.New("invalid name")
+	ErrCircuitOpen       = errors.New("circuit open")
+	ErrUndefinedState    = errors.New("undefined state")
+	ErrNotSerializable   = errors.New("not serializable")
+	ErrNotFound          = errors.New("not found")
+)
+
+// reasons of the not allowed transit
+var (
+	ErrUnknownTransit = fmt.Errorf("unknown transit: %w", ErrTransitNotAllowed)
+	ErrWrongState     = fmt.Errorf("wrong source state: %w", ErrTransitNotAllowed)
+	ErrGuardRejected  = fmt.Errorf("guard rejected: %w", ErrTransitNotAllowed)
+	ErrDisabled       = fmt.Errorf("transit disabled: %w", ErrTransitNotAllowed)
 )
 
 // Data for the transit
+// state compared with states of the transitions by String() so it can be other type than the transition states
 type Data interface {
 	GetState() fmt.Stringer
 }
@@ -21,122 +52,1181 @@ type Data interface {
 // Process set state for the data
 type Process func(ctx context.Context, data Data) (Data, error)
 
-// Middleware run other logic
+// Middleware run other logic, long running middleware should respect context cancellation
 type Middleware func(ctx context.Context, data Data, next Process) (Data, error)
 
+// Guard check business rules for the data
+type Guard func(ctx context.Context, data Data) (bool, error)
+
+// AnyState in src match any state of the data, the same as empty src
+var AnyState fmt.Stringer = anyState{}
+
+type anyState struct{}
+
+func (anyState) String() string {
+	return "*"
+}
+
 // Transition configure
 type Transition struct {
+	// Src states allowed for the transition, nil, empty or containing AnyState allow any state
+	// StateGroup in src allow the parent and child states
+	// the slice is shared by the added transition and must not be changed after Add, read it by Sources
 	Src        []fmt.Stringer
 	Dst        fmt.Stringer
 	Middleware Middleware
+	// Middlewares run in order before Middleware, on Add they are chained into Middleware
+	Middlewares []Middleware
+	// Guard run after src check, transition not available when it returns false
+	Guard Guard
+	// Idempotent allow apply for data already in dst, data returned unchanged without middleware and apply
+	Idempotent bool
+	// Disabled transition is not available, zero value keeps transition enabled
+	Disabled bool
+	// DstFunc resolve dst by data on apply and override Dst
+	// static Dst is still used by graph analysis and exporters
+	DstFunc func(ctx context.Context, data Data) (fmt.Stringer, error)
+	// Inverse transit name which reverse the transition, used by Undo
+	Inverse fmt.Stringer
+	// ExceptSrc allow the transition from any state not in the list, can't be used with Src
+	ExceptSrc []fmt.Stringer
+	// SrcFunc match src state by predicate, can't be used with Src and ExceptSrc, state nil for data without state
+	SrcFunc func(state fmt.Stringer) bool
+	// Meta labels for the caller like ui text or permission, ignored by apply and included in json
+	Meta map[string]any
+	// MiddlewareNames of the registered middleware run after custom middleware and before Middlewares
+	MiddlewareNames []string
+	// Compensate transit name which roll back the transition when later step of ApplyChain failed
+	Compensate fmt.Stringer
+	// Weight cost of the transition used by CheapestPath, less than 1 counted as 1
+	Weight int
+	// ApplyTimeout deadline of the apply callback only, guards, middleware and hooks not limited, zero means no deadline
+	// apply must respect the context, only its context.DeadlineExceeded error labeled by the timeout
+	ApplyTimeout time.Duration
+
+	// layers names of the chained middleware, set on Add
+	layers []string
+}
+
+// weight get cost of the transition, at least 1
+func (tr *Transition) weight() int {
+	if tr.Weight < 1 {
+		return 1
+	}
+
+	return tr.Weight
 }
 
-// Can check state by src
+// Sources get copy of the src states
+func (tr *Transition) Sources() []fmt.Stringer {
+	if tr.Src == nil {
+		return nil
+	}
+
+	return append(make([]fmt.Stringer, 0, len(tr.Src)), tr.Src...)
+}
+
+// Can check state by src func, except src or src, states compared by String()
 func (tr *Transition) Can(data Data) bool {
+	return tr.can(data, stringEqual)
+}
+
+// can check state by src func, except src or src with the state equality
+func (tr *Transition) can(data Data, equal StateEqual) bool {
+	if tr.SrcFunc != nil {
+		return tr.SrcFunc(data.GetState())
+	}
+	if len(tr.ExceptSrc) > 0 {
+		for _, src := range tr.ExceptSrc {
+			if matchState(src, data.GetState(), equal) {
+				return false
+			}
+		}
+		return true
+	}
+	if tr.anySrc() {
+		return true
+	}
+	for _, src := range tr.Src {
+		if matchState(src, data.GetState(), equal) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeSrc describe allowed src states for errors, except src prefixed by "not " and src func shown as "<func>"
+func (tr *Transition) describeSrc() []string {
+	if tr.SrcFunc != nil {
+		return []string{funcState}
+	}
+
+	out := make([]string, 0, len(tr.Src)+len(tr.ExceptSrc))
+	for _, src := range tr.Src {
+		out = append(out, stateKey(src))
+	}
+	for _, src := range tr.ExceptSrc {
+		out = append(out, "not "+stateKey(src))
+	}
+
+	return out
+}
+
+// anySrc check transition allowed from any state
+func (tr *Transition) anySrc() bool {
+	if tr.SrcFunc != nil || len(tr.ExceptSrc) > 0 {
+		return false
+	}
 	if len(tr.Src) == 0 {
 		return true
 	}
 	for _, src := range tr.Src {
-		if data.GetState() == src {
+		if src == AnyState {
 			return true
 		}
 	}
+
 	return false
 }
 
+// idempotent check transition is idempotent and data already in dst
+func (tr *Transition) idempotent(data Data, equal StateEqual) bool {
+	return tr.Idempotent && equal(data.GetState(), tr.Dst)
+}
+
+// allow check transition enabled, state by src and then guard
+func (tr *Transition) allow(ctx context.Context, data Data, equal StateEqual) (bool, error) {
+	if tr.Disabled || !tr.can(data, equal) {
+		return false, nil
+	}
+	if tr.Guard == nil {
+		return true, nil
+	}
+
+	return tr.Guard(ctx, data)
+}
+
+// validate transition configuration on add
+func (tr *Transition) validate(name fmt.Stringer) error {
+	if tr.Dst == nil && tr.DstFunc == nil {
+		return fmt.Errorf("transit %q without dst: %w", name, ErrInvalidTransition)
+	}
+	if len(tr.Src) > 0 && len(tr.ExceptSrc) > 0 {
+		return fmt.Errorf("transit %q with src and except src: %w", name, ErrInvalidTransition)
+	}
+	if tr.SrcFunc != nil && (len(tr.Src) > 0 || len(tr.ExceptSrc) > 0) {
+		return fmt.Errorf("transit %q with src func and src: %w", name, ErrInvalidTransition)
+	}
+
+	return nil
+}
+
+// clone transition with copy of src and meta
+func (tr *Transition) clone() *Transition {
+	out := *tr
+	if tr.Src != nil {
+		out.Src = make([]fmt.Stringer, len(tr.Src))
+		copy(out.Src, tr.Src)
+	}
+	if tr.ExceptSrc != nil {
+		out.ExceptSrc = make([]fmt.Stringer, len(tr.ExceptSrc))
+		copy(out.ExceptSrc, tr.ExceptSrc)
+	}
+	if tr.MiddlewareNames != nil {
+		out.MiddlewareNames = append([]string(nil), tr.MiddlewareNames...)
+	}
+	if tr.Meta != nil {
+		out.Meta = make(map[string]any, len(tr.Meta))
+		for key, val := range tr.Meta {
+			out.Meta[key] = val
+		}
+	}
+
+	return &out
+}
+
+// sameSrc check both transitions have the same set of src or except src states
+// transitions with src func never the same
+func (tr *Transition) sameSrc(other *Transition) bool {
+	if tr.SrcFunc != nil || other.SrcFunc != nil {
+		return false
+	}
+	if tr.anySrc() || other.anySrc() {
+		return tr.anySrc() && other.anySrc()
+	}
+
+	return sameStates(tr.Src, other.Src) && sameStates(tr.ExceptSrc, other.ExceptSrc)
+}
+
+// sameStates check both lists have the same set of states
+func sameStates(states, other []fmt.Stringer) bool {
+	set := make(map[string]bool, len(states))
+	for _, s := range states {
+		set[s.String()] = true
+	}
+	otherSet := make(map[string]bool, len(other))
+	for _, s := range other {
+		if !set[s.String()] {
+			return false
+		}
+		otherSet[s.String()] = true
+	}
+
+	return len(set) == len(otherSet)
+}
+
+// NamedTransition transition with the transit name
+type NamedTransition struct {
+	Name       fmt.Stringer
+	Transition *Transition
+}
+
 // Apply state to data
 type Apply func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error)
 
-// NewWorkflow create new workflow
-func NewWorkflow(apply Apply, mw ...Middleware) *Workflow {
-	return &Workflow{
+// NewWorkflow create new workflow, panics when apply is nil or transitions of the options are invalid
+func NewWorkflow(apply Apply, opts ...Option) *Workflow {
+	w, err := NewWorkflowE(apply, opts...)
+	if err != nil {
+		panic("workflow: " + err.Error())
+	}
+
+	return w
+}
+
+// NewWorkflowMW create new workflow with global middleware, accepts middleware slices and func literals
+// which NewWorkflow took before options, panics as NewWorkflow
+func NewWorkflowMW(apply Apply, mw ...Middleware) *Workflow {
+	return NewWorkflow(apply, WithMiddleware(mw...))
+}
+
+// NewWorkflowE create new workflow and return error of the transitions added by options, panics when apply is nil
+// transitions of the options checked and chained as by Add after all options applied
+func NewWorkflowE(apply Apply, opts ...Option) (*Workflow, error) {
+	if apply == nil {
+		panic("workflow: apply must not be nil")
+	}
+
+	w := &Workflow{
 		apply:       apply,
-		mw:          chainProcess(mw...),
-		transitions: make(map[fmt.Stringer]*Transition),
+		equal:       stringEqual,
+		transitions: make(map[fmt.Stringer][]*Transition),
+	}
+	for _, opt := range opts {
+		opt.configure(w)
+	}
+	w.mw = chainProcess(w.cancelCheck, w.middleware...)
+
+	pending := w.pending
+	w.pending = nil
+	for _, nt := range pending {
+		tr, err := w.prepare(nt.Name, nt.Transition.clone(), nil)
+		if err != nil {
+			return nil, err
+		}
+		w.set(nt.Name, append(w.transitions[nt.Name], tr))
 	}
+
+	return w, nil
 }
 
 // Workflow configure transitions
 type Workflow struct {
-	transitions map[fmt.Stringer]*Transition
+	transitions map[fmt.Stringer][]*Transition
+	order       []fmt.Stringer
+	pending     []NamedTransition
 	apply       Apply
 	mw          Middleware
-	mu          sync.Mutex
+	middleware  []Middleware
+	initials    []fmt.Stringer
+	factory     Factory
+	store       Store
+	enter       map[string][]Hook
+	leave       map[string][]Hook
+	before      []BeforeHook
+	after       []AfterHook
+	onError     []ErrorHook
+	onSuccess   []SuccessHook
+	mu          sync.RWMutex
+	frozen      int32
+	// hooks number of the registered hooks, apply skip hook snapshots without them
+	hooks int32
+	subs  map[<-chan Event]chan Event
+	subMu sync.Mutex
+	// subCount number of the subscriptions, publish skip the lock without them
+	subCount    int32
+	stats       *stats
+	registry    map[string]Middleware
+	stateMeta   map[string]stateInfo
+	equal       StateEqual
+	keyed       *keyedMutex
+	strict      bool
+	cancelCheck bool
 }
 
-// Get transition by data and transit
-func (w *Workflow) Get(data Data, transit fmt.Stringer) *Transition {
-	tr, ok := w.transitions[transit]
-	if !ok || !tr.Can(data) {
-		return nil
+// Freeze make transitions immutable, after that read transitions without lock
+// and Add, Replace return ErrFrozen
+func (w *Workflow) Freeze() {
+	w.mu.Lock()
+	atomic.StoreInt32(&w.frozen, 1)
+	w.mu.Unlock()
+}
+
+// Clone copy transitions to new not frozen workflow with the same apply and middleware
+// subscriptions and stats are not copied, clone of workflow with stats starts with empty stats
+// keyed mutex shared so the clone serialize apply of the same data with the source workflow
+func (w *Workflow) Clone() *Workflow {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	out := &Workflow{
+		apply:       w.apply,
+		equal:       w.equal,
+		keyed:       w.keyed,
+		strict:      w.strict,
+		cancelCheck: w.cancelCheck,
+		mw:          w.mw,
+		middleware:  w.middleware,
+		transitions: make(map[fmt.Stringer][]*Transition, len(w.transitions)),
+		enter:       copyHooks(w.enter),
+		leave:       copyHooks(w.leave),
+		before:      append([]BeforeHook(nil), w.before...),
+		after:       append([]AfterHook(nil), w.after...),
+		onError:     append([]ErrorHook(nil), w.onError...),
+		onSuccess:   append([]SuccessHook(nil), w.onSuccess...),
+		hooks:       atomic.LoadInt32(&w.hooks),
+		initials:    w.initials,
+		factory:     w.factory,
+		store:       w.store,
+		registry:    make(map[string]Middleware, len(w.registry)),
+	}
+	for name, mw := range w.registry {
+		out.registry[name] = mw
+	}
+	if w.stateMeta != nil {
+		out.stateMeta = make(map[string]stateInfo, len(w.stateMeta))
+		for key, info := range w.stateMeta {
+			out.stateMeta[key] = info
+		}
+	}
+	if w.stats != nil {
+		out.stats = &stats{counts: make(map[fmt.Stringer]int)}
+	}
+	out.order = append([]fmt.Stringer(nil), w.order...)
+	for name, trs := range w.transitions {
+		out.transitions[name] = make([]*Transition, len(trs))
+		for i, tr := range trs {
+			out.transitions[name][i] = tr.clone()
+		}
+	}
+
+	return out
+}
+
+// lookup transitions by name, writers never change returned items so it safe to read after unlock
+func (w *Workflow) lookup(name fmt.Stringer) []*Transition {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	return w.transitions[name]
+}
+
+// lookupAll transitions of each name with one lock, writers never change returned items
+func (w *Workflow) lookupAll(names []fmt.Stringer) [][]*Transition {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	out := make([][]*Transition, len(names))
+	for i, name := range names {
+		out[i] = w.transitions[name]
+	}
+
+	return out
+}
+
+// collect transitions matched by the filter in registration order of the names
+func (w *Workflow) collect(match func(tr *Transition) bool) []NamedTransition {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	var out []NamedTransition
+	for _, name := range w.order {
+		for _, tr := range w.transitions[name] {
+			if match(tr) {
+				out = append(out, NamedTransition{Name: name, Transition: tr})
+			}
+		}
 	}
+
+	return out
+}
+
+// Get transition by data and transit, guard run with background context
+// transitions with the same name checked in registration order and the first allowed returned,
+// so when sources overlap the earlier registered transition wins, Apply resolve the same way
+func (w *Workflow) Get(data Data, transit fmt.Stringer) *Transition {
+	tr, _ := w.get(context.Background(), data, transit)
+
 	return tr
 }
 
+// get transition allowed by src and guard
+func (w *Workflow) get(ctx context.Context, data Data, transit fmt.Stringer) (*Transition, error) {
+	tr, err := w.check(ctx, data, transit)
+
+	switch {
+	case err == nil:
+		return tr, nil
+	case errors.Is(err, ErrTransitNotAllowed):
+		return nil, fmt.Errorf("transit %q from %q: %w", transit, stateKey(data.GetState()), notAllowedError{reason: err})
+	default:
+		return nil, fmt.Errorf("transit %q guard: %w", transit, guardError{err: err})
+	}
+}
+
+// check transition by src and guard and describe the reason when it not allowed
+func (w *Workflow) check(ctx context.Context, data Data, transit fmt.Stringer) (*Transition, error) {
+	return w.evaluate(ctx, data, transit, w.lookup(transit))
+}
+
+// evaluate transitions of the transit by src and guard and describe the reason when none allowed
+func (w *Workflow) evaluate(ctx context.Context, data Data, transit fmt.Stringer, trs []*Transition) (*Transition, error) {
+	if len(trs) == 0 {
+		return nil, fmt.Errorf("transit %q: %w", transit, ErrUnknownTransit)
+	}
+
+	var (
+		src      []string
+		matched  bool
+		disabled int
+	)
+	for _, tr := range trs {
+		if tr.Disabled {
+			disabled++
+			continue
+		}
+		if tr.idempotent(data, w.equal) {
+			return tr, nil
+		}
+		if !tr.can(data, w.equal) {
+			src = append(src, tr.describeSrc()...)
+			continue
+		}
+
+		matched = true
+		allow, err := tr.allow(ctx, data, w.equal)
+		if err != nil {
+			return nil, err
+		}
+		if allow {
+			return tr, nil
+		}
+	}
+
+	if disabled == len(trs) {
+		return nil, fmt.Errorf("transit %q: %w", transit, ErrDisabled)
+	}
+	if !matched {
+		return nil, fmt.Errorf("transit %q from %q expected %q: %w", transit, stateKey(data.GetState()), src, ErrWrongState)
+	}
+
+	return nil, fmt.Errorf("transit %q: %w", transit, ErrGuardRejected)
+}
+
 // Add new transition and custom middleware
+// several transitions can be added with the same name when they have different src
 func (w *Workflow) Add(name fmt.Stringer, transit *Transition, mw ...Middleware) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	if _, ok := w.transitions[name]; ok {
-		return ErrDuplicateTransit
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	tr, err := w.prepare(name, transit, mw)
+	if err != nil {
+		return err
+	}
+	w.set(name, append(w.transitions[name], tr))
+
+	return nil
+}
+
+// AddReversible add transition from src to dst and the reverse one from dst to src with the same custom middleware
+// transitions are the Inverse of each other, nothing added when any of the names already exists
+func (w *Workflow) AddReversible(name, reverseName fmt.Stringer, src, dst fmt.Stringer, mw ...Middleware) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	if name.String() == reverseName.String() {
+		return fmt.Errorf("transit %q: %w", name, ErrDuplicateTransit)
+	}
+	for _, n := range []fmt.Stringer{name, reverseName} {
+		if _, ok := w.transitions[n]; ok {
+			return fmt.Errorf("transit %q: %w", n, ErrDuplicateTransit)
+		}
 	}
 
+	forward, err := w.prepare(name, &Transition{Src: []fmt.Stringer{src}, Dst: dst, Inverse: reverseName}, mw)
+	if err != nil {
+		return err
+	}
+	reverse, err := w.prepare(reverseName, &Transition{Src: []fmt.Stringer{dst}, Dst: src, Inverse: name}, mw)
+	if err != nil {
+		return err
+	}
+	w.set(name, []*Transition{forward})
+	w.set(reverseName, []*Transition{reverse})
+
+	return nil
+}
+
+// prepare validate transition and chain its middleware without adding, caller must hold the lock
+func (w *Workflow) prepare(name fmt.Stringer, transit *Transition, mw []Middleware) (*Transition, error) {
+	if err := transit.validate(name); err != nil {
+		return nil, err
+	}
+	if err := w.checkStates(name, transit); err != nil {
+		return nil, err
+	}
+	if w.duplicate(name, transit) {
+		return nil, ErrDuplicateTransit
+	}
+	layers := layerNames(transit, len(mw))
+	mw, err := w.withNamed(name, transit, mw)
+	if err != nil {
+		return nil, err
+	}
+	transit.layers = layers
+
+	return w.chainTransition(transit, mw), nil
+}
+
+// duplicate check transition with the same name and src exists, caller must hold the lock
+func (w *Workflow) duplicate(name fmt.Stringer, transit *Transition) bool {
+	for _, tr := range w.transitions[name] {
+		if tr.sameSrc(transit) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Replace all existing transitions with the name by the transition and custom middleware
+func (w *Workflow) Replace(name fmt.Stringer, transit *Transition, mw ...Middleware) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	if _, ok := w.transitions[name]; !ok {
+		return ErrTransitNotFound
+	}
+	if err := transit.validate(name); err != nil {
+		return err
+	}
+	if err := w.checkStates(name, transit); err != nil {
+		return err
+	}
+	layers := layerNames(transit, len(mw))
+	mw, err := w.withNamed(name, transit, mw)
+	if err != nil {
+		return err
+	}
+	transit.layers = layers
+	w.set(name, []*Transition{w.chainTransition(transit, mw)})
+
+	return nil
+}
+
+// SetEnabled enable or disable all transitions with the name
+func (w *Workflow) SetEnabled(name fmt.Stringer, on bool) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+
+	trs, ok := w.transitions[name]
+	if !ok {
+		return ErrTransitNotFound
+	}
+
+	// copy on write, readers may hold previous transitions
+	updated := make([]*Transition, len(trs))
+	for i, tr := range trs {
+		updated[i] = tr.clone()
+		updated[i].Disabled = !on
+	}
+	w.transitions[name] = updated
+
+	return nil
+}
+
+// Merge copy transitions of other workflow, apply and middleware of w are kept
+// nothing copied when any transition has the same name and src as existing one or has undefined state in strict mode
+func (w *Workflow) Merge(other *Workflow) error {
+	trs := other.OrderedTransitions()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	for _, nt := range trs {
+		if err := w.checkStates(nt.Name, nt.Transition); err != nil {
+			return err
+		}
+		if w.duplicate(nt.Name, nt.Transition) {
+			return fmt.Errorf("transit %q: %w", nt.Name, ErrDuplicateTransit)
+		}
+	}
+	for _, nt := range trs {
+		w.set(nt.Name, append(w.transitions[nt.Name], nt.Transition))
+	}
+
+	return nil
+}
+
+// layerNames get names of the transition middleware in the chain order, only registered middleware has the name
+func layerNames(transit *Transition, custom int) []string {
+	names := make([]string, 0, custom+len(transit.MiddlewareNames)+len(transit.Middlewares)+1)
+	for i := 0; i < custom; i++ {
+		names = append(names, anonymous)
+	}
+	names = append(names, transit.MiddlewareNames...)
+	for range transit.Middlewares {
+		names = append(names, anonymous)
+	}
 	if transit.Middleware != nil {
-		mw = append(mw, transit.Middleware)
+		names = append(names, anonymous)
 	}
-	transit.Middleware = chainProcess(mw...)
-	w.transitions[name] = transit
+
+	return names
+}
+
+// chainTransition set to the transition middleware chained in order custom, Middlewares and Middleware
+// Middleware stays nil without middleware so apply skips the chain
+func (w *Workflow) chainTransition(transit *Transition, mw []Middleware) *Transition {
+	chain := make([]Middleware, 0, len(mw)+len(transit.Middlewares)+1)
+	chain = append(chain, mw...)
+	chain = append(chain, transit.Middlewares...)
+	if transit.Middleware != nil {
+		chain = append(chain, transit.Middleware)
+	}
+	transit.Middleware = nil
+	if len(chain) > 0 {
+		transit.Middleware = chainProcess(w.cancelCheck, chain...)
+	}
+	transit.Middlewares = nil
+
+	return transit
+}
+
+// Remove all transitions with the name and report whether it existed, frozen workflow not changed
+func (w *Workflow) Remove(name fmt.Stringer) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return false
+	}
+	if _, ok := w.transitions[name]; !ok {
+		return false
+	}
+	delete(w.transitions, name)
+	order := make([]fmt.Stringer, 0, len(w.order)-1)
+	for _, n := range w.order {
+		if n != name {
+			order = append(order, n)
+		}
+	}
+	w.order = order
+
+	return true
+}
+
+// Clear remove all transitions and keep apply, middleware and hooks
+// applying transit see transitions before or after clear, never partially cleared
+func (w *Workflow) Clear() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.frozen == 1 {
+		return ErrFrozen
+	}
+	w.transitions = make(map[fmt.Stringer][]*Transition)
+	w.order = nil
 
 	return nil
 }
 
+// set transitions of the name, new name added to the end of registration order, caller must hold the lock
+func (w *Workflow) set(name fmt.Stringer, trs []*Transition) {
+	if _, ok := w.transitions[name]; !ok {
+		w.order = append(w.order, name)
+	}
+	w.transitions[name] = trs
+}
+
+// HasTransition check transition with the name registered
+func (w *Workflow) HasTransition(name fmt.Stringer) bool {
+	return len(w.lookup(name)) > 0
+}
+
+// Transition get copy of the first registered transition with the name without checking data
+func (w *Workflow) Transition(name fmt.Stringer) (*Transition, bool) {
+	trs := w.lookup(name)
+	if len(trs) == 0 {
+		return nil, false
+	}
+
+	return trs[0].clone(), true
+}
+
+// Transitions get copy of all transitions sorted by name
+// transitions with the same name keep registration order
+func (w *Workflow) Transitions() []NamedTransition {
+	out := w.OrderedTransitions()
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Name.String() < out[j].Name.String()
+	})
+
+	return out
+}
+
+// OrderedTransitions get copy of all transitions in registration order of the names
+// transitions with the same name kept together in registration order, replaced name keeps its position
+func (w *Workflow) OrderedTransitions() []NamedTransition {
+	if atomic.LoadInt32(&w.frozen) == 0 {
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+	}
+
+	out := make([]NamedTransition, 0, len(w.order))
+	for _, name := range w.order {
+		for _, tr := range w.transitions[name] {
+			out = append(out, NamedTransition{Name: name, Transition: tr.clone()})
+		}
+	}
+
+	return out
+}
+
+// Available get sorted transit names allowed for the data like Can, guard run with background context
+func (w *Workflow) Available(data Data) []fmt.Stringer {
+	var (
+		names []fmt.Stringer
+		trs   = make(map[fmt.Stringer][]*Transition)
+	)
+	for _, nt := range w.collect(func(tr *Transition) bool { return true }) {
+		if _, ok := trs[nt.Name]; !ok {
+			names = append(names, nt.Name)
+		}
+		trs[nt.Name] = append(trs[nt.Name], nt.Transition)
+	}
+
+	ctx := context.Background()
+	out := make([]fmt.Stringer, 0, len(names))
+	for _, name := range names {
+		if _, err := w.evaluate(ctx, data, name, trs[name]); err == nil {
+			out = append(out, name)
+		}
+	}
+
+	sortStringers(out)
+
+	return out
+}
+
 // Can check can transit by src data
 func (w *Workflow) Can(data Data, transit fmt.Stringer) bool {
 	return w.Get(data, transit) != nil
 }
 
-// Apply transit with middleware
-func (w *Workflow) Apply(ctx context.Context, data Data, transit fmt.Stringer) (Data, error) {
-	return w.mw(ctx, data, func(ctx context.Context, data Data) (Data, error) {
-		if tr := w.Get(data, transit); tr != nil {
-			return tr.Middleware(ctx, data, func(ctx context.Context, data Data) (Data, error) {
-				return w.apply(ctx, data, tr.Dst)
-			})
+// CanAll check can transit for each transit like Can with one lock, guard run with background context
+func (w *Workflow) CanAll(data Data, transits ...fmt.Stringer) map[fmt.Stringer]bool {
+	ctx := context.Background()
+	candidates := w.lookupAll(transits)
+	out := make(map[fmt.Stringer]bool, len(transits))
+	for i, transit := range transits {
+		_, err := w.evaluate(ctx, data, transit, candidates[i])
+		out[transit] = err == nil
+	}
+
+	return out
+}
+
+// CanErr check can transit by src data and return the reason when it not allowed
+func (w *Workflow) CanErr(data Data, transit fmt.Stringer) error {
+	_, err := w.check(context.Background(), data, transit)
+
+	return err
+}
+
+// CanCtx check can transit by src data and guard with context
+func (w *Workflow) CanCtx(ctx context.Context, data Data, transit fmt.Stringer) bool {
+	tr, _ := w.get(ctx, data, transit)
+
+	return tr != nil
+}
+
+// Result of the applied transit
+type Result struct {
+	Transit    fmt.Stringer
+	Data       Data
+	Transition *Transition
+	From       fmt.Stringer
+	To         fmt.Stringer
+}
+
+// Apply transit with middleware and options of the call
+func (w *Workflow) Apply(ctx context.Context, data Data, transit fmt.Stringer, opts ...ApplyOption) (Data, error) {
+	res, err := w.ApplyResult(ctx, data, transit, opts...)
+
+	return res.Data, err
+}
+
+// ApplyResult apply transit with middleware and return resolved transition with previous and new state
+// done context returns its error before any hook, middleware or apply run
+func (w *Workflow) ApplyResult(ctx context.Context, data Data, transit fmt.Stringer, opts ...ApplyOption) (Result, error) {
+	cfg := newApplyConfig(opts)
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	return w.applyResult(ctx, data, transit, nil, cfg.after...)
+}
+
+// newApplyConfig configure the call by options, config without options stays on the stack
+func newApplyConfig(opts []ApplyOption) applyConfig {
+	if len(opts) == 0 {
+		return applyConfig{}
+	}
+
+	var cfg applyConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// resolver get transition allowed for the data, nil resolver use get
+type resolver func(ctx context.Context, data Data, transit fmt.Stringer) (*Transition, error)
+
+// applyResult run hooks, middleware and apply, extra after hooks run after the registered
+func (w *Workflow) applyResult(ctx context.Context, data Data, transit fmt.Stringer, resolve resolver, extra ...AfterHook) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		w.runError(ctx, data, transit, err)
+
+		return Result{Transit: transit, From: data.GetState()}, err
+	}
+
+	var unlock func()
+	if w.keyed != nil {
+		var err error
+		if unlock, err = w.keyed.lock(ctx, data); err != nil {
+			w.runError(ctx, data, transit, err)
+
+			return Result{Transit: transit, From: data.GetState()}, err
 		}
-		return nil, ErrTransitNotAllowed
+		// released after apply so hooks can apply the next transit to the same data, defer release on panic
+		defer func() {
+			if unlock != nil {
+				unlock()
+			}
+		}()
+	}
+
+	ctx = withTransit(ctx, transit)
+
+	var (
+		before []BeforeHook
+		after  []AfterHook
+	)
+	if atomic.LoadInt32(&w.hooks) > 0 {
+		before, after = w.applyHooks()
+	}
+	if len(extra) > 0 {
+		after = append(after[:len(after):len(after)], extra...)
+	}
+
+	res := Result{Transit: transit, From: data.GetState()}
+	err := runBefore(ctx, data, transit, before)
+	if err == nil {
+		res.Data, res.Transition, err = w.process(ctx, data, transit, resolve, nil)
+	}
+	res.To = stateOf(res.Data)
+	if unlock != nil {
+		unlock()
+		unlock = nil
+	}
+
+	runAfter(ctx, data, res.Data, transit, err, after)
+	if err != nil {
+		w.runError(ctx, data, transit, err)
+
+		return res, err
+	}
+
+	w.runSuccess(ctx, res)
+	w.count(transit)
+	w.publish(Event{Transit: transit, From: res.From, To: res.To, Data: res.Data})
+
+	return res, nil
+}
+
+// ApplyByState apply the single transit allowed for the data with the dst state
+// several allowed transits return error matched by ErrAmbiguousTransit unless WithSelector choose one of them
+// error hooks get nil transit when no single transit is resolved
+func (w *Workflow) ApplyByState(ctx context.Context, data Data, dst fmt.Stringer, opts ...ApplyOption) (Data, error) {
+	cfg := newApplyConfig(opts)
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+		defer cancel()
+	}
+
+	candidates := w.collect(func(tr *Transition) bool {
+		return tr.Dst != nil && w.equal(tr.Dst, dst)
 	})
+
+	allowed := make([]NamedTransition, 0, 1)
+	for _, nt := range candidates {
+		allow, err := nt.Transition.allow(ctx, data, w.equal)
+		if err != nil {
+			err = fmt.Errorf("transit %q guard: %w", nt.Name, guardError{err: err})
+			w.runError(ctx, data, nt.Name, err)
+
+			return nil, err
+		}
+		if allow {
+			allowed = append(allowed, nt)
+		}
+	}
+	if len(allowed) > 1 && cfg.selector != nil {
+		allowed = selectTransition(allowed, cfg.selector)
+	}
+
+	switch len(allowed) {
+	case 0:
+		err := fmt.Errorf("dst %q from %q: %w", dst, stateKey(data.GetState()), ErrTransitNotAllowed)
+		w.runError(ctx, data, nil, err)
+
+		return nil, err
+	case 1:
+		tr := allowed[0].Transition
+		res, err := w.applyResult(ctx, data, allowed[0].Name, func(ctx context.Context, data Data, transit fmt.Stringer) (*Transition, error) {
+			allow, err := tr.allow(ctx, data, w.equal)
+			if err != nil {
+				return nil, fmt.Errorf("transit %q guard: %w", transit, guardError{err: err})
+			}
+			if !allow {
+				return nil, fmt.Errorf("transit %q from %q: %w", transit, stateKey(data.GetState()), notAllowedError{reason: ErrGuardRejected})
+			}
+
+			return tr, nil
+		}, cfg.after...)
+
+		return res.Data, err
+	}
+
+	names := make([]fmt.Stringer, len(allowed))
+	for i, nt := range allowed {
+		names[i] = nt.Name
+	}
+	sortStringers(names)
+
+	err := fmt.Errorf("dst %q transits %q: %w", dst, names, ErrAmbiguousTransit)
+	w.runError(ctx, data, nil, err)
+
+	return nil, err
 }
 
-// chainProcess add chain by Process
-func chainProcess(handleFunc ...Middleware) Middleware {
+// selectTransition keep the transition chosen by selector from transitions in registration order
+// all transitions returned when selector choose nil or unknown transition
+func selectTransition(allowed []NamedTransition, selector func([]*Transition) *Transition) []NamedTransition {
+	trs := make([]*Transition, len(allowed))
+	for i, nt := range allowed {
+		trs[i] = nt.Transition
+	}
+	selected := selector(trs)
+	for i, nt := range allowed {
+		if selected != nil && nt.Transition == selected {
+			return allowed[i : i+1]
+		}
+	}
+
+	return allowed
+}
+
+// DryRun check transit by guard and middleware without state hooks and apply
+// middleware with side effects still run
+func (w *Workflow) DryRun(ctx context.Context, data Data, transit fmt.Stringer) error {
+	_, _, err := w.process(withTransit(ctx, transit), data, transit, nil, func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+
+	return err
+}
+
+// process run middleware and apply transit
+// without global middleware resolve and apply run directly to skip the chain
+func (w *Workflow) process(ctx context.Context, data Data, transit fmt.Stringer, resolve resolver, apply Apply) (Data, *Transition, error) {
+	if len(w.middleware) == 0 {
+		return w.resolveApply(ctx, data, transit, resolve, apply)
+	}
+
+	var resolved *Transition
+	res, err := w.mw(ctx, data, func(ctx context.Context, data Data) (Data, error) {
+		res, tr, err := w.resolveApply(ctx, data, transit, resolve, apply)
+		if tr != nil {
+			resolved = tr
+		}
+
+		return res, err
+	})
+
+	return res, resolved, err
+}
+
+// resolveApply resolve transition and run its middleware and apply, transition nil when not resolved
+// transition without middleware apply directly
+func (w *Workflow) resolveApply(ctx context.Context, data Data, transit fmt.Stringer, resolve resolver, apply Apply) (Data, *Transition, error) {
+	var (
+		tr  *Transition
+		err error
+	)
+	if resolve == nil {
+		tr, err = w.get(ctx, data, transit)
+	} else {
+		tr, err = resolve(ctx, data, transit)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if tr.idempotent(data, w.equal) {
+		return data, tr, nil
+	}
+
+	dst := tr.Dst
+	if tr.DstFunc != nil {
+		if dst, err = tr.DstFunc(ctx, data); err != nil {
+			return nil, tr, fmt.Errorf("transit %q dst: %w", transit, err)
+		}
+	}
+	if call := callFromContext(ctx); call != nil {
+		call.transition, call.src, call.dst = tr, data.GetState(), dst
+	}
+
+	if tr.Middleware == nil {
+		res, err := w.applyDst(ctx, data, transit, tr, dst, apply)
+
+		return res, tr, err
+	}
+
+	res, err := tr.Middleware(ctx, data, func(ctx context.Context, data Data) (Data, error) {
+		return w.applyDst(ctx, data, transit, tr, dst, apply)
+	})
+
+	return res, tr, err
+}
+
+// applyDst run apply of the resolved transition, nil apply run state hooks and apply of the workflow
+func (w *Workflow) applyDst(ctx context.Context, data Data, transit fmt.Stringer, tr *Transition, dst fmt.Stringer, apply Apply) (Data, error) {
+	var (
+		res Data
+		err error
+	)
+	if apply == nil {
+		res, err = w.transit(ctx, data, dst, tr.ApplyTimeout)
+	} else {
+		res, err = apply(ctx, data, dst)
+	}
+	if err != nil {
+		return res, fmt.Errorf("transit %q: %w", transit, err)
+	}
+
+	return res, nil
+}
+
+// stateOf get state of the data, nil data has nil state
+func stateOf(data Data) fmt.Stringer {
+	if data == nil {
+		return nil
+	}
+
+	return data.GetState()
+}
+
+// sortStringers sort by string value
+func sortStringers(s []fmt.Stringer) {
+	sort.Slice(s, func(i, j int) bool {
+		return s[i].String() < s[j].String()
+	})
+}
+
+// chainProcess add chain by Process, with check the done context stops the chain before each next layer and next process
+// layers are copied once and each run allocates only its call state
+func chainProcess(check bool, handleFunc ...Middleware) Middleware {
 	n := len(handleFunc)
 
-	if n > 1 {
-		lastI := n - 1
+	if n == 0 {
 		return func(ctx context.Context, data Data, next Process) (Data, error) {
-			var (
-				chainHandler Process
-				curI         int
-			)
-			chainHandler = func(currentCtx context.Context, currentData Data) (Data, error) {
-				if curI == lastI {
-					return next(currentCtx, data)
-				}
-				curI++
-				data, err := handleFunc[curI](currentCtx, currentData, chainHandler)
-				curI--
-
-				return data, err
-			}
-			return handleFunc[0](ctx, data, chainHandler)
+			return next(ctx, data)
 		}
 	}
 
-	if n == 1 {
-		return handleFunc[0]
+	if n == 1 && !check {
+		mw := handleFunc[0]
+		return func(ctx context.Context, data Data, next Process) (Data, error) {
+			return callLayer(ctx, data, mw, 0, next)
+		}
 	}
 
+	layers := append([]Middleware(nil), handleFunc...)
 	return func(ctx context.Context, data Data, next Process) (Data, error) {
-		return next(ctx, data)
+		c := &chainCall{layers: layers, check: check, next: next}
+		c.handler = c.process
+
+		return callLayer(ctx, data, layers[0], 0, c.handler)
+	}
+}
+
+// chainCall state of the single run of the chain
+type chainCall struct {
+	layers  []Middleware
+	check   bool
+	cur     int
+	next    Process
+	handler Process
+}
+
+// process run the layer after the current or next process after the last layer
+func (c *chainCall) process(ctx context.Context, data Data) (Data, error) {
+	if c.check {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 	}
+	if c.cur == len(c.layers)-1 {
+		return c.next(ctx, data)
+	}
+
+	c.cur++
+	// restore the position even when the layer panics and a recovering layer above calls next again
+	defer func() { c.cur-- }()
+
+	return callLayer(ctx, data, c.layers[c.cur], c.cur, c.handler)
 }
@@ -2,8 +2,10 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 	"testing"
 
@@ -79,6 +81,12 @@ func TestNewWorkflow(t *testing.T) {
 	require.NotNil(t, w.apply)
 }
 
+func TestNewWorkflow_NilApply(t *testing.T) {
+	require.PanicsWithValue(t, "workflow: apply must not be nil", func() {
+		NewWorkflow(nil)
+	})
+}
+
 func TestWorkflow_Apply(t *testing.T) {
 	ctx := context.Background()
 	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
@@ -91,7 +99,7 @@ func TestWorkflow_Apply(t *testing.T) {
 	data := testData{}
 	ex, err := w.Apply(ctx, data, toDone)
 	require.Nil(t, ex)
-	require.EqualError(t, err, "transit not allowed")
+	require.EqualError(t, err, `transit "to done" from "": transit not allowed`)
 	exNew, err := w.Apply(ctx, data, toNew)
 	require.Nil(t, err)
 	require.Equal(t, newState, exNew.GetState())
@@ -164,6 +172,60 @@ func TestWorkflow_Add(t *testing.T) {
 	require.EqualError(t, w.Add(toNew, &Transition{Dst: doneState}), "duplicate transit")
 }
 
+func TestWorkflow_Add_WithoutMiddleware(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, WithCancelCheck())
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	tr, ok := w.Transition(toDone)
+	require.True(t, ok)
+	require.Nil(t, tr.Middleware)
+	require.Nil(t, w.Replace(toDone, tr))
+	require.Nil(t, w.Snapshot().Transitions[0].Middleware)
+
+	ex, err := w.Apply(ctx, testData{state: newState}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+}
+
+func TestWorkflow_Replace(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	mwf := &testMWFactory{}
+	require.EqualError(t, w.Replace(toNew, &Transition{Dst: newState}), "transit not found")
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, mwf.Success(t, "new")))
+	require.Nil(t, w.Replace(toNew, &Transition{
+		Dst:        doneState,
+		Middleware: mwf.Success(t, "replace"),
+	}, mwf.Success(t, "replace add")))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+	require.Equal(t, []string{"replace add", "replace"}, mwf.ex)
+}
+
+func TestWorkflow_Remove(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.False(t, w.Remove(toNew))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.True(t, w.Can(testData{}, toNew))
+	require.True(t, w.Remove(toNew))
+	require.False(t, w.Can(testData{}, toNew))
+	require.False(t, w.Remove(toNew))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+}
+
 func TestWorkflow_Can(t *testing.T) {
 	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
 		return data, nil
@@ -175,3 +237,1005 @@ func TestWorkflow_Can(t *testing.T) {
 	require.False(t, w.Can(data, toCancel))
 	require.False(t, w.Can(data, toDone))
 }
+
+func TestWorkflow_Transitions(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Len(t, w.Transitions(), 0)
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	trs := w.Transitions()
+	require.Len(t, trs, 3)
+	require.Equal(t, toCancel, trs[0].Name)
+	require.Equal(t, toDone, trs[1].Name)
+	require.Equal(t, toNew, trs[2].Name)
+	require.Equal(t, []fmt.Stringer{newState, doneState}, trs[0].Transition.Src)
+
+	trs[0].Transition.Src[0] = cancelState
+	trs[0].Transition.Dst = newState
+	require.Equal(t, cancelState, w.transitions[toCancel][0].Dst)
+	require.Equal(t, newState, w.transitions[toCancel][0].Src[0])
+}
+
+func TestWorkflow_Available(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{testState("")}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	require.Equal(t, []fmt.Stringer{toNew}, w.Available(testData{state: testState("")}))
+	require.Equal(t, []fmt.Stringer{toCancel, toDone}, w.Available(testData{state: newState}))
+	require.Equal(t, []fmt.Stringer{toCancel}, w.Available(testData{state: doneState}))
+
+	available := w.Available(testData{state: cancelState})
+	require.NotNil(t, available)
+	require.Len(t, available, 0)
+
+	ship, shipped := testTransit("ship"), testState("shipped")
+	require.Nil(t, w.Add(ship, &Transition{Dst: shipped, Src: []fmt.Stringer{doneState}, Idempotent: true}))
+	require.True(t, w.Can(testData{state: shipped}, ship))
+	require.Equal(t, []fmt.Stringer{ship}, w.Available(testData{state: shipped}))
+}
+
+type testCtxKey struct{}
+
+func TestWorkflow_Guard(t *testing.T) {
+	ctx := context.Background()
+	errGuard := errors.New("guard failed")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{
+		Dst: doneState,
+		Src: []fmt.Stringer{newState},
+		Guard: func(ctx context.Context, data Data) (bool, error) {
+			return ctx.Value(testCtxKey{}) != nil, nil
+		},
+	}))
+	require.Nil(t, w.Add(toCancel, &Transition{
+		Dst: cancelState,
+		Guard: func(ctx context.Context, data Data) (bool, error) {
+			return false, errGuard
+		},
+	}))
+
+	data := testData{state: newState}
+	require.False(t, w.Can(data, toDone))
+	require.False(t, w.Can(data, toCancel))
+	require.Equal(t, []fmt.Stringer{}, w.Available(data))
+
+	guardCtx := context.WithValue(ctx, testCtxKey{}, true)
+	require.True(t, w.CanCtx(guardCtx, data, toDone))
+	require.False(t, w.CanCtx(guardCtx, testData{state: cancelState}, toDone))
+
+	_, err := w.Apply(ctx, data, toDone)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+
+	ex, err := w.Apply(guardCtx, data, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+
+	_, err = w.Apply(ctx, data, toCancel)
+	require.True(t, errors.Is(err, errGuard))
+}
+
+func TestWorkflow_CanErr(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{
+		Dst: cancelState,
+		Guard: func(ctx context.Context, data Data) (bool, error) {
+			return false, nil
+		},
+	}))
+
+	data := testData{state: testState("draft")}
+	require.Nil(t, w.CanErr(data, toNew))
+
+	err := w.CanErr(data, testTransit("to archive"))
+	require.True(t, errors.Is(err, ErrUnknownTransit))
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	require.EqualError(t, err, `transit "to archive": unknown transit: transit not allowed`)
+
+	err = w.CanErr(data, toDone)
+	require.True(t, errors.Is(err, ErrWrongState))
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	require.EqualError(t, err, `transit "to done" from "draft" expected ["new"]: wrong source state: transit not allowed`)
+	err = w.CanErr(testData{}, toDone)
+	require.EqualError(t, err, `transit "to done" from "" expected ["new"]: wrong source state: transit not allowed`)
+
+	archive := testTransit("to archive")
+	require.Nil(t, w.Add(archive, &Transition{Dst: doneState, ExceptSrc: []fmt.Stringer{testState("draft")}}))
+	require.Nil(t, w.Add(archive, &Transition{Dst: doneState, SrcFunc: func(state fmt.Stringer) bool {
+		return false
+	}}))
+	err = w.CanErr(data, archive)
+	require.True(t, errors.Is(err, ErrWrongState))
+	require.EqualError(t, err, `transit "to archive" from "draft" expected ["not draft" "<func>"]: wrong source state: transit not allowed`)
+
+	err = w.CanErr(data, toCancel)
+	require.True(t, errors.Is(err, ErrGuardRejected))
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+}
+
+func TestWorkflow_Apply_ConcurrentAdd(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = w.Add(testTransit(fmt.Sprintf("transit %d", i)), &Transition{Dst: doneState})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_, _ = w.Apply(ctx, testData{}, toNew)
+			_ = w.Can(testData{}, testTransit(fmt.Sprintf("transit %d", i)))
+		}
+	}()
+	wg.Wait()
+
+	require.Len(t, w.Transitions(), 101)
+}
+
+func TestWorkflow_Freeze(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	w.Freeze()
+
+	require.True(t, errors.Is(w.Add(toDone, &Transition{Dst: doneState}), ErrFrozen))
+	require.True(t, errors.Is(w.Replace(toNew, &Transition{Dst: doneState}), ErrFrozen))
+	require.False(t, w.Remove(toNew))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+	require.Equal(t, []fmt.Stringer{toNew}, w.Available(testData{}))
+}
+
+func TestWorkflow_Clone(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	w.Freeze()
+
+	clone := w.Clone()
+	require.Nil(t, clone.Add(toCancel, &Transition{Dst: cancelState}))
+	require.True(t, clone.Remove(toNew))
+	clone.transitions[toDone][0].Src[0] = cancelState
+
+	require.False(t, w.Can(testData{}, toCancel))
+	require.True(t, w.Can(testData{}, toNew))
+	require.Equal(t, []fmt.Stringer{newState}, w.transitions[toDone][0].Src)
+
+	ex, err := clone.Apply(ctx, testData{}, toCancel)
+	require.Nil(t, err)
+	require.Equal(t, cancelState, ex.GetState())
+}
+
+func TestWorkflow_Merge(t *testing.T) {
+	ctx := context.Background()
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}
+	mwf := &testMWFactory{}
+	w := NewWorkflow(apply, mwf.Success(t, "global"))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+
+	other := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return nil, errors.New("other apply")
+	}, mwf.Success(t, "other global"))
+	require.Nil(t, other.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}, mwf.Success(t, "done")))
+	require.Nil(t, w.Merge(other))
+
+	ex, err := w.Apply(ctx, testData{state: newState}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+	require.Equal(t, []string{"global", "done"}, mwf.ex)
+
+	dup := NewWorkflow(apply)
+	require.Nil(t, dup.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Nil(t, dup.Add(toNew, &Transition{Dst: newState}))
+	require.True(t, errors.Is(w.Merge(dup), ErrDuplicateTransit))
+	require.False(t, w.Can(testData{}, toCancel))
+}
+
+func TestWorkflow_ApplyResult(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	res, err := w.ApplyResult(ctx, testData{state: newState}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, testData{state: doneState}, res.Data)
+	require.Equal(t, newState, res.From)
+	require.Equal(t, doneState, res.To)
+	require.Equal(t, w.Get(testData{state: newState}, toDone), res.Transition)
+
+	res, err = w.ApplyResult(ctx, testData{state: doneState}, toDone)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	require.Nil(t, res.Data)
+	require.Nil(t, res.Transition)
+	require.Equal(t, doneState, res.From)
+}
+
+func TestWorkflow_ApplyByState(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(testTransit("abort"), &Transition{Dst: cancelState}))
+
+	ex, err := w.ApplyByState(ctx, testData{state: newState}, doneState)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+
+	_, err = w.ApplyByState(ctx, testData{state: cancelState}, doneState)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+
+	ex, err = w.ApplyByState(ctx, testData{state: doneState}, cancelState)
+	require.Nil(t, err)
+	require.Equal(t, cancelState, ex.GetState())
+
+	_, err = w.ApplyByState(ctx, testData{state: newState}, cancelState)
+	require.True(t, errors.Is(err, ErrAmbiguousTransit))
+	require.EqualError(t, err, `dst "cancel" transits ["abort" "to cancel"]: ambiguous transit`)
+
+	var applied fmt.Stringer
+	ex, err = w.ApplyByState(ctx, testData{state: newState}, cancelState, WithSelector(func(trs []*Transition) *Transition {
+		require.Len(t, trs, 2)
+		require.Nil(t, trs[1].Src)
+		return trs[0]
+	}), WithApplyAfter(func(ctx context.Context, data Data, transit fmt.Stringer, err error) {
+		applied = transit
+	}))
+	require.Nil(t, err)
+	require.Equal(t, cancelState, ex.GetState())
+	require.Equal(t, toCancel, applied)
+
+	_, err = w.ApplyByState(ctx, testData{state: newState}, cancelState, WithSelector(func(trs []*Transition) *Transition {
+		return nil
+	}))
+	require.True(t, errors.Is(err, ErrAmbiguousTransit))
+}
+
+func TestWorkflow_ApplyByState_OnError(t *testing.T) {
+	ctx := context.Background()
+	errGuard := errors.New("guard")
+	var ex []string
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Nil(t, w.Add(testTransit("abort"), &Transition{Dst: cancelState}))
+	require.Nil(t, w.Add(testTransit("fail"), &Transition{Dst: newState, Guard: func(ctx context.Context, data Data) (bool, error) {
+		return false, errGuard
+	}}))
+	require.Nil(t, w.OnError(func(ctx context.Context, data Data, transit fmt.Stringer, err error) {
+		ex = append(ex, fmt.Sprintf("%v: %v", transit, err))
+	}))
+
+	_, err := w.ApplyByState(ctx, testData{}, doneState)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	_, err = w.ApplyByState(ctx, testData{}, cancelState)
+	require.True(t, errors.Is(err, ErrAmbiguousTransit))
+	_, err = w.ApplyByState(ctx, testData{}, newState)
+	require.True(t, errors.Is(err, errGuard))
+
+	require.Equal(t, []string{
+		`<nil>: dst "done" from "": transit not allowed`,
+		`<nil>: dst "cancel" transits ["abort" "to cancel"]: ambiguous transit`,
+		`fail: transit "fail" guard: guard`,
+	}, ex)
+}
+
+func TestWorkflow_DryRun(t *testing.T) {
+	ctx := context.Background()
+	errMW := errors.New("middleware")
+	var calls int
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		calls++
+		return data, nil
+	})
+	mwf := &testMWFactory{}
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, mwf.Success(t, "new")))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}, func(ctx context.Context, data Data, next Process) (Data, error) {
+		return nil, errMW
+	}))
+
+	require.Nil(t, w.DryRun(ctx, testData{}, toNew))
+	require.Equal(t, []string{"new"}, mwf.ex)
+	require.True(t, errors.Is(w.DryRun(ctx, testData{}, toDone), ErrTransitNotAllowed))
+	require.True(t, errors.Is(w.DryRun(ctx, testData{}, toCancel), errMW))
+	require.Equal(t, 0, calls)
+}
+
+func TestWorkflow_Add_SameName(t *testing.T) {
+	ctx := context.Background()
+	approve := testTransit("approve")
+	approved, review := testState("approved"), testState("review")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(approve, &Transition{Dst: review, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(approve, &Transition{Dst: approved, Src: []fmt.Stringer{review}}))
+	require.True(t, errors.Is(w.Add(approve, &Transition{Dst: doneState, Src: []fmt.Stringer{review}}), ErrDuplicateTransit))
+
+	exReview, err := w.Apply(ctx, testData{state: newState}, approve)
+	require.Nil(t, err)
+	require.Equal(t, review, exReview.GetState())
+
+	exApproved, err := w.Apply(ctx, exReview, approve)
+	require.Nil(t, err)
+	require.Equal(t, approved, exApproved.GetState())
+
+	err = w.CanErr(exApproved, approve)
+	require.EqualError(t, err, `transit "approve" from "approved" expected ["new" "review"]: wrong source state: transit not allowed`)
+
+	trs := w.Transitions()
+	require.Len(t, trs, 2)
+	require.Equal(t, review, trs[0].Transition.Dst)
+	require.Equal(t, approved, trs[1].Transition.Dst)
+	require.Equal(t, []fmt.Stringer{approve}, w.Available(testData{state: review}))
+
+	require.Nil(t, w.Replace(approve, &Transition{Dst: doneState}))
+	require.Len(t, w.Transitions(), 1)
+}
+
+func TestWorkflow_Apply_WrapErrors(t *testing.T) {
+	ctx := context.Background()
+	errApply := errors.New("apply")
+	errGuard := errors.New("guard")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return nil, errApply
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{
+		Dst: cancelState,
+		Guard: func(ctx context.Context, data Data) (bool, error) {
+			return false, errGuard
+		},
+	}))
+
+	_, err := w.Apply(ctx, testData{state: cancelState}, toDone)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	require.EqualError(t, err, `transit "to done" from "cancel": transit not allowed`)
+
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, errApply))
+	require.EqualError(t, err, `transit "to new": apply`)
+
+	_, err = w.Apply(ctx, testData{}, toCancel)
+	require.True(t, errors.Is(err, errGuard))
+	require.EqualError(t, err, `transit "to cancel" guard: guard`)
+}
+
+func TestWorkflow_Apply_Middlewares(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	mwf := &testMWFactory{}
+	tr := &Transition{
+		Dst:         doneState,
+		Middleware:  mwf.Success(t, "middleware"),
+		Middlewares: []Middleware{mwf.Success(t, "list 1"), mwf.Success(t, "list 2")},
+	}
+	require.Nil(t, w.Add(toDone, tr, mwf.Success(t, "add")))
+	require.Nil(t, tr.Middlewares)
+
+	ex, err := w.Apply(ctx, testData{}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+	require.Equal(t, []string{"add", "list 1", "list 2", "middleware"}, mwf.ex)
+}
+
+func TestWorkflow_Apply_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	shipped := testState("shipped")
+	var calls int
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		calls++
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	mwf := &testMWFactory{}
+	require.Nil(t, w.Add(testTransit("ship"), &Transition{
+		Dst:        shipped,
+		Src:        []fmt.Stringer{newState},
+		Idempotent: true,
+	}, mwf.Success(t, "ship")))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	ex, err := w.Apply(ctx, testData{state: newState}, testTransit("ship"))
+	require.Nil(t, err)
+	require.Equal(t, shipped, ex.GetState())
+
+	ex, err = w.Apply(ctx, ex, testTransit("ship"))
+	require.Nil(t, err)
+	require.Equal(t, shipped, ex.GetState())
+	require.Equal(t, 1, calls)
+	require.Equal(t, []string{"ship"}, mwf.ex)
+
+	_, err = w.Apply(ctx, testData{state: doneState}, toDone)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+	_, err = w.Apply(ctx, testData{state: doneState}, testTransit("ship"))
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+}
+
+func TestWorkflow_HasTransition(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+
+	require.True(t, w.HasTransition(toDone))
+	require.False(t, w.HasTransition(toNew))
+
+	_, ok := w.Transition(toNew)
+	require.False(t, ok)
+
+	tr, ok := w.Transition(toDone)
+	require.True(t, ok)
+	require.Equal(t, doneState, tr.Dst)
+	require.Equal(t, []fmt.Stringer{newState}, tr.Src)
+
+	tr.Src[0] = cancelState
+	require.True(t, w.Can(testData{state: newState}, toDone))
+}
+
+func TestWorkflow_SetEnabled(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	mwf := &testMWFactory{}
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, mwf.Success(t, "new")))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Disabled: true}))
+	require.True(t, errors.Is(w.SetEnabled(toCancel, false), ErrTransitNotFound))
+
+	require.False(t, w.Can(testData{}, toDone))
+	require.True(t, errors.Is(w.CanErr(testData{}, toDone), ErrDisabled))
+
+	require.Nil(t, w.SetEnabled(toNew, false))
+	require.False(t, w.Can(testData{}, toNew))
+	require.Equal(t, []fmt.Stringer{}, w.Available(testData{}))
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+
+	require.Nil(t, w.SetEnabled(toNew, true))
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+	require.Equal(t, []string{"new"}, mwf.ex)
+
+	w.Freeze()
+	require.True(t, errors.Is(w.SetEnabled(toNew, false), ErrFrozen))
+}
+
+func TestWorkflow_Apply_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		calls++
+		return data, nil
+	})
+	mwf := &testMWFactory{}
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, mwf.Success(t, "new")))
+	require.Nil(t, w.Before(func(ctx context.Context, data Data, transit fmt.Stringer) error {
+		calls++
+		return nil
+	}))
+
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, ex)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, 0, calls)
+	require.Len(t, mwf.ex, 0)
+}
+
+func TestWithCancelCheck(t *testing.T) {
+	var calls int
+	apply := func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		calls++
+		return data, nil
+	}
+	cancelMW := func(cancel context.CancelFunc) Middleware {
+		return func(ctx context.Context, data Data, next Process) (Data, error) {
+			cancel()
+			return next(ctx, data)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mwf := &testMWFactory{}
+	w := NewWorkflow(apply, WithCancelCheck())
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, mwf.Success(t, "first"), cancelMW(cancel), mwf.Success(t, "third")))
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, []string{"first"}, mwf.ex)
+	require.Equal(t, 0, calls)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	w = NewWorkflow(apply, WithTransitions(map[fmt.Stringer]*Transition{toNew: {Dst: newState}}), WithCancelCheck(), cancelMW(cancel))
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, context.Canceled))
+	require.Equal(t, 0, calls)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	mwf = &testMWFactory{}
+	w = NewWorkflow(apply)
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}, mwf.Success(t, "first"), cancelMW(cancel), mwf.Success(t, "third")))
+	_, err = w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, []string{"first", "third"}, mwf.ex)
+	require.Equal(t, 1, calls)
+}
+
+func TestWorkflow_Apply_DstFunc(t *testing.T) {
+	ctx := context.Background()
+	tier2, tier3 := testState("tier2"), testState("tier3")
+	escalate := testTransit("escalate")
+	errDst := errors.New("dst")
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.True(t, errors.Is(w.Add(escalate, &Transition{}), ErrInvalidTransition))
+	require.Nil(t, w.Add(escalate, &Transition{
+		Src: []fmt.Stringer{newState, tier2},
+		Dst: tier2,
+		DstFunc: func(ctx context.Context, data Data) (fmt.Stringer, error) {
+			switch data.GetState() {
+			case newState:
+				return tier2, nil
+			case tier2:
+				return tier3, nil
+			}
+			return nil, errDst
+		},
+	}))
+
+	ex, err := w.Apply(ctx, testData{state: newState}, escalate)
+	require.Nil(t, err)
+	require.Equal(t, tier2, ex.GetState())
+	ex, err = w.Apply(ctx, ex, escalate)
+	require.Nil(t, err)
+	require.Equal(t, tier3, ex.GetState())
+
+	require.Nil(t, w.Replace(escalate, &Transition{DstFunc: func(ctx context.Context, data Data) (fmt.Stringer, error) {
+		return nil, errDst
+	}}))
+	_, err = w.Apply(ctx, testData{}, escalate)
+	require.True(t, errors.Is(err, errDst))
+	require.True(t, errors.Is(w.Replace(escalate, &Transition{}), ErrInvalidTransition))
+	require.Equal(t, []fmt.Stringer{}, w.States())
+}
+
+func TestWorkflow_AnyState(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{AnyState}}))
+	require.True(t, errors.Is(w.Add(toCancel, &Transition{Dst: cancelState}), ErrDuplicateTransit))
+
+	require.True(t, w.Can(testData{state: doneState}, toCancel))
+	ex, err := w.Apply(ctx, testData{state: newState}, toCancel)
+	require.Nil(t, err)
+	require.Equal(t, cancelState, ex.GetState())
+
+	require.Equal(t, []fmt.Stringer{cancelState, newState}, w.States())
+	require.Equal(t, []fmt.Stringer{toCancel, toNew}, w.Outgoing(doneState))
+}
+
+func TestWorkflow_ExceptSrc(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.True(t, errors.Is(w.Add(toCancel, &Transition{
+		Dst:       cancelState,
+		Src:       []fmt.Stringer{newState},
+		ExceptSrc: []fmt.Stringer{doneState},
+	}), ErrInvalidTransition))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{testState("draft")}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, ExceptSrc: []fmt.Stringer{doneState, cancelState}}))
+	require.True(t, errors.Is(w.Add(toCancel, &Transition{Dst: cancelState, ExceptSrc: []fmt.Stringer{cancelState, doneState}}), ErrDuplicateTransit))
+
+	require.True(t, w.Can(testData{}, toCancel))
+	require.False(t, w.Can(testData{state: doneState}, toCancel))
+	require.True(t, errors.Is(w.CanErr(testData{state: cancelState}, toCancel), ErrWrongState))
+
+	ex, err := w.Apply(ctx, testData{state: newState}, toCancel)
+	require.Nil(t, err)
+	require.Equal(t, cancelState, ex.GetState())
+
+	require.Equal(t, []fmt.Stringer{toCancel, toDone}, w.Outgoing(newState))
+	require.Equal(t, []fmt.Stringer{}, w.Outgoing(doneState))
+	require.Equal(t, []fmt.Stringer{cancelState, doneState}, w.Terminal())
+}
+
+func TestWorkflow_SrcFunc(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	draft := func(state fmt.Stringer) bool {
+		return state != nil && strings.HasPrefix(state.String(), "draft_")
+	}
+	require.True(t, errors.Is(w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{doneState}, SrcFunc: draft}), ErrInvalidTransition))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, SrcFunc: draft}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(testTransit("to draft"), &Transition{Dst: testState("draft_order"), Src: []fmt.Stringer{doneState}}))
+
+	require.False(t, w.Can(testData{}, toNew))
+	require.False(t, w.Can(testData{state: doneState}, toNew))
+	ex, err := w.Apply(ctx, testData{state: testState("draft_order")}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+
+	require.Equal(t, []fmt.Stringer{toNew}, w.Outgoing(testState("draft_order")))
+	require.Equal(t, []fmt.Stringer{toNew}, w.Incoming(newState))
+	require.Nil(t, w.Validate())
+}
+
+func TestWorkflow_Get_RegistrationOrder(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	first := &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}
+	second := &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}
+	require.Nil(t, w.Add(toDone, first))
+	require.Nil(t, w.Add(toDone, second))
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, doneState, w.Get(testData{state: newState}, toDone).Dst)
+	}
+	require.Equal(t, cancelState, w.Get(testData{state: doneState}, toDone).Dst)
+
+	ex, err := w.Apply(ctx, testData{state: newState}, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+}
+
+func TestWorkflow_CanAll(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Idempotent: true, Src: []fmt.Stringer{testState("draft")}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Guard: func(ctx context.Context, data Data) (bool, error) {
+		return false, nil
+	}}))
+
+	data := testData{state: newState}
+	require.Equal(t, map[fmt.Stringer]bool{
+		toNew:                  true,
+		toDone:                 true,
+		toCancel:               false,
+		testTransit("unknown"): false,
+	}, w.CanAll(data, toNew, toDone, toCancel, testTransit("unknown")))
+	for _, transit := range []fmt.Stringer{toNew, toDone, toCancel} {
+		require.Equal(t, w.Can(data, transit), w.CanAll(data, transit)[transit])
+	}
+	require.Equal(t, map[fmt.Stringer]bool{}, w.CanAll(data))
+}
+
+func TestWorkflow_Clear(t *testing.T) {
+	ctx := context.Background()
+	mwf := &testMWFactory{}
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	}, mwf.Success(t, "global"))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState}))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_, _ = w.Apply(ctx, testData{}, toNew)
+		}
+	}()
+	require.Nil(t, w.Clear())
+	wg.Wait()
+
+	require.Len(t, w.Transitions(), 0)
+	_, err := w.Apply(ctx, testData{}, toNew)
+	require.True(t, errors.Is(err, ErrTransitNotAllowed))
+
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	mwf.ex = nil
+	ex, err := w.Apply(ctx, testData{}, toNew)
+	require.Nil(t, err)
+	require.Equal(t, newState, ex.GetState())
+	require.Equal(t, []string{"global"}, mwf.ex)
+
+	w.Freeze()
+	require.True(t, errors.Is(w.Clear(), ErrFrozen))
+	require.True(t, w.HasTransition(toNew))
+}
+
+type testDBState struct {
+	name string
+}
+
+func (s *testDBState) String() string {
+	return s.name
+}
+
+func TestWorkflow_Can_StringEqual(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState, Src: []fmt.Stringer{testState("draft")}, Idempotent: true}))
+
+	loaded := testData{state: &testDBState{name: "new"}}
+	require.True(t, (&Transition{Src: []fmt.Stringer{newState}}).Can(loaded))
+	require.True(t, w.Can(loaded, toDone))
+	require.Nil(t, w.CanErr(loaded, toNew))
+	ex, err := w.Apply(ctx, loaded, toDone)
+	require.Nil(t, err)
+	require.Equal(t, doneState, ex.GetState())
+	require.False(t, w.Can(testData{state: &testDBState{name: "done"}}, toDone))
+}
+
+func TestWorkflow_AddReversible(t *testing.T) {
+	ctx := context.Background()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		d := data.(testData)
+		d.state = dst
+		return d, nil
+	})
+	enable, disable := testTransit("enable"), testTransit("disable")
+	enabled, disabled := testState("enabled"), testState("disabled")
+	mwf := &testMWFactory{}
+	require.Nil(t, w.AddReversible(enable, disable, disabled, enabled, mwf.Success(t, "toggle")))
+
+	ex, err := w.Apply(ctx, testData{state: disabled}, enable)
+	require.Nil(t, err)
+	require.Equal(t, enabled, ex.GetState())
+	ex, err = w.Apply(ctx, ex, disable)
+	require.Nil(t, err)
+	require.Equal(t, disabled, ex.GetState())
+	require.Equal(t, []string{"toggle", "toggle"}, mwf.ex)
+	require.False(t, w.Can(testData{state: disabled}, disable))
+
+	tr, ok := w.Transition(enable)
+	require.True(t, ok)
+	require.Equal(t, disable, tr.Inverse)
+	res, err := w.ApplyResult(ctx, testData{state: disabled}, enable)
+	require.Nil(t, err)
+	undo, err := w.Undo(ctx, res)
+	require.Nil(t, err)
+	require.Equal(t, disabled, undo.To)
+
+	err = w.AddReversible(testTransit("open"), enable, disabled, enabled)
+	require.True(t, errors.Is(err, ErrDuplicateTransit))
+	require.EqualError(t, err, `transit "enable": duplicate transit`)
+	_, ok = w.Transition(testTransit("open"))
+	require.False(t, ok)
+
+	err = w.AddReversible(toNew, toNew, newState, doneState)
+	require.True(t, errors.Is(err, ErrDuplicateTransit))
+	err = w.AddReversible(testTransit("close"), testState("close"), newState, doneState)
+	require.True(t, errors.Is(err, ErrDuplicateTransit))
+	require.EqualError(t, err, `transit "close": duplicate transit`)
+	_, ok = w.Transition(testTransit("close"))
+	require.False(t, ok)
+}
+
+func TestWorkflow_OrderedTransitions(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	}, WithTransitions(map[fmt.Stringer]*Transition{toNew: {Dst: newState}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState}}))
+	require.Nil(t, w.Add(testTransit("archive"), &Transition{Dst: testState("archive"), Src: []fmt.Stringer{doneState}}))
+	require.Nil(t, w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{cancelState}}))
+
+	names := func(trs []NamedTransition) []fmt.Stringer {
+		out := make([]fmt.Stringer, len(trs))
+		for i, nt := range trs {
+			out[i] = nt.Name
+		}
+		return out
+	}
+	ordered := w.OrderedTransitions()
+	require.Equal(t, []fmt.Stringer{toNew, toDone, toDone, toCancel, testTransit("archive")}, names(ordered))
+	require.Equal(t, []fmt.Stringer{newState}, ordered[1].Transition.Src)
+	require.Equal(t, []fmt.Stringer{cancelState}, ordered[2].Transition.Src)
+	require.Equal(t, []fmt.Stringer{testTransit("archive"), toCancel, toDone, toDone, toNew}, names(w.Transitions()))
+
+	require.Nil(t, w.Replace(toDone, &Transition{Dst: doneState}))
+	require.True(t, w.Remove(toCancel))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState}))
+	require.Equal(t, []fmt.Stringer{toNew, toDone, testTransit("archive"), toCancel}, names(w.OrderedTransitions()))
+	require.Equal(t, names(w.OrderedTransitions()), names(w.Clone().OrderedTransitions()))
+
+	require.Nil(t, w.Clear())
+	require.Len(t, w.OrderedTransitions(), 0)
+}
+
+func TestTransition_Sources(t *testing.T) {
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	require.Nil(t, w.Add(toNew, &Transition{Dst: newState}))
+	require.Nil(t, w.Add(toCancel, &Transition{Dst: cancelState, Src: []fmt.Stringer{newState, doneState}}))
+
+	tr := w.Get(testData{state: newState}, toCancel)
+	src := tr.Sources()
+	require.Equal(t, []fmt.Stringer{newState, doneState}, src)
+	src[0] = cancelState
+	_ = append(src[:1], cancelState)
+	require.Equal(t, []fmt.Stringer{newState, doneState}, tr.Sources())
+	require.True(t, w.Can(testData{state: doneState}, toCancel))
+
+	require.Nil(t, w.Get(testData{}, toNew).Sources())
+}
+
+func TestChainProcess(t *testing.T) {
+	ctx := context.Background()
+	mwf := &testMWFactory{}
+	toState := func(state fmt.Stringer) Middleware {
+		return func(ctx context.Context, data Data, next Process) (Data, error) {
+			return next(ctx, testData{state: state})
+		}
+	}
+	chain := chainProcess(false, mwf.Success(t, "first"), mwf.Success(t, "second"), toState(doneState))
+
+	for i := 0; i < 2; i++ {
+		res, err := chain(ctx, testData{state: newState}, func(ctx context.Context, data Data) (Data, error) {
+			return data, nil
+		})
+		require.Nil(t, err)
+		require.Equal(t, testData{state: doneState}, res)
+	}
+	require.Equal(t, []string{"first", "second", "first", "second"}, mwf.ex)
+}
+
+func BenchmarkChainProcess(b *testing.B) {
+	ctx := context.Background()
+	pass := func(ctx context.Context, data Data, next Process) (Data, error) {
+		return next(ctx, data)
+	}
+	chain := chainProcess(false, pass, pass, pass)
+	next := func(ctx context.Context, data Data) (Data, error) {
+		return data, nil
+	}
+	var data Data = testData{state: newState}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := chain(ctx, data, next); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func newBenchWorkflow(b *testing.B, mw ...Middleware) *Workflow {
+	b.Helper()
+	w := NewWorkflow(func(ctx context.Context, data Data, dst fmt.Stringer) (Data, error) {
+		return data, nil
+	})
+	if err := w.Add(toDone, &Transition{Dst: doneState, Src: []fmt.Stringer{newState}}, mw...); err != nil {
+		b.Fatal(err)
+	}
+
+	return w
+}
+
+func BenchmarkApply(b *testing.B) {
+	ctx := context.Background()
+	w := newBenchWorkflow(b)
+	var data Data = testData{state: newState}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Apply(ctx, data, toDone); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkApply_Middleware(b *testing.B) {
+	ctx := context.Background()
+	pass := func(ctx context.Context, data Data, next Process) (Data, error) {
+		return next(ctx, data)
+	}
+	w := newBenchWorkflow(b, pass, pass, pass)
+	var data Data = testData{state: newState}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Apply(ctx, data, toDone); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCan(b *testing.B) {
+	w := newBenchWorkflow(b)
+	var data Data = testData{state: newState}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !w.Can(data, toDone) {
+			b.Fatal("transit not allowed")
+		}
+	}
+}